@@ -2,22 +2,152 @@ package mailgen
 
 import (
 	"bytes"
+	"crypto/rand"
 	"fmt"
 	htmltemplate "html/template"
+	"io"
+	"log/slog"
+	"maps"
+	"net/mail"
+	"net/textproto"
+	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/akfaiz/go-mailgen/templates"
 	"github.com/vanng822/go-premailer/premailer"
+	"golang.org/x/net/idna"
 )
 
+// Fallback placements supported by Builder.FallbackPlacement.
+const (
+	// FallbackEnd renders all action fallbacks together at the end of the email.
+	FallbackEnd = "end"
+	// FallbackAfterAction renders each action fallback immediately after its button.
+	FallbackAfterAction = "after-action"
+	// FallbackFooter is an alias of FallbackEnd.
+	FallbackFooter = "footer"
+	// FallbackInline is an alias of FallbackAfterAction.
+	FallbackInline = "inline"
+)
+
+// Newline modes supported by Builder.PlainTextNewlineMode.
+const (
+	// NewlineCompact collapses three or more consecutive newlines into a single blank line.
+	NewlineCompact = "compact"
+	// NewlineSingle collapses consecutive newlines, including whitespace-only lines, into a single newline.
+	NewlineSingle = "single"
+	// NewlinePreserve keeps consecutive newlines as they are.
+	NewlinePreserve = "preserve"
+)
+
+// Alert levels supported by Builder.Alert.
+const (
+	// AlertInfo renders a blue alert for neutral information.
+	AlertInfo = "info"
+	// AlertSuccess renders a green alert, e.g. for a completed payment.
+	AlertSuccess = "success"
+	// AlertWarning renders an orange alert, e.g. for a password change.
+	AlertWarning = "warning"
+	// AlertDanger renders a red alert, e.g. for a failed payment or a suspicious sign-in.
+	AlertDanger = "danger"
+)
+
+// Doctypes supported by Builder.Doctype.
+const (
+	// DoctypeXHTMLTransitional is the XHTML 1.0 Transitional doctype, the most widely supported by email clients.
+	DoctypeXHTMLTransitional = `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" ` +
+		`"http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">`
+	// DoctypeHTML5 is the HTML5 doctype.
+	DoctypeHTML5 = "<!DOCTYPE html>"
+)
+
+// Priority is the priority of an email message, set via Builder.Priority.
+type Priority int
+
+// Priorities supported by Builder.Priority.
+const (
+	// PriorityNormal is the default priority, which does not add any headers.
+	PriorityNormal Priority = iota
+	// PriorityLow flags the email as low priority.
+	PriorityLow
+	// PriorityHigh flags the email as high priority, e.g. for security alerts or payment failures.
+	PriorityHigh
+)
+
+// String returns the name of the priority, e.g. "high".
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
 // Product represents the product information used in the email.
 type Product struct {
-	Name      string
-	Link      string
-	Logo      string // Optional logo URL
-	Copyright string
+	Name      string `json:"name,omitempty"`
+	Link      string `json:"link,omitempty"`
+	Logo      string `json:"logo,omitempty"` // Optional logo URL
+	Copyright string `json:"copyright,omitempty"`
+
+	// LogoAlt is the alternative text of the logo, displayed when images are not loaded. Default is Name.
+	LogoAlt string `json:"logoAlt,omitempty"`
+	// LogoWidth is the width of the logo in pixels, e.g. "120". Default is the natural width, up to 400 pixels.
+	LogoWidth string `json:"logoWidth,omitempty"`
+	// LogoHeight is the height of the logo in pixels, e.g. "40". Default is the natural height, up to 50 pixels.
+	LogoHeight string `json:"logoHeight,omitempty"`
+
+	// CopyrightStartYear renders the generated copyright as a year range, e.g. "© 2019–2025 Acme.".
+	// Ignored when Copyright is set or when it is not before the current year.
+	CopyrightStartYear int `json:"copyrightStartYear,omitempty"`
+	// NoCopyrightSuffix omits the "All rights reserved." suffix from the generated copyright.
+	NoCopyrightSuffix bool `json:"noCopyrightSuffix,omitempty"`
+}
+
+// Signature represents the sender's signature rendered below the salutation, set via Builder.Signature.
+type Signature struct {
+	// Name is the name of the sender, e.g. "Jane Doe".
+	Name string `json:"name,omitempty"`
+	// Title is an optional line below the name, e.g. "Account Manager, Acme".
+	Title string `json:"title,omitempty"`
+	// ImageURL is the URL of an optional photo displayed next to the name, rendered at 48x48 pixels.
+	ImageURL string `json:"imageURL,omitempty"`
+}
+
+// UnsubscribeOption configures the unsubscribe link set via Builder.Unsubscribe.
+type UnsubscribeOption struct {
+	// Mailto is an additional mailto address listed in the List-Unsubscribe header, e.g. "unsubscribe@example.com".
+	Mailto string
+	// ShowLink if true, an unsubscribe link is rendered in the footer of the email.
+	ShowLink bool
+	// Text is the text of the footer link. Default is "Unsubscribe".
+	Text string
+}
+
+// PreferenceCenterOption configures the preference center link set via Builder.PreferenceCenter.
+type PreferenceCenterOption struct {
+	// Header if true, the URL is also included in the List-Manage header of the email.
+	Header bool
+}
+
+// SocialLink represents a link to a social profile displayed in the footer of the email.
+type SocialLink struct {
+	// Name is the name of the social network, e.g. "Twitter". It is used as the link text or icon alt text.
+	Name string `json:"name,omitempty"`
+	// URL is the URL of the social profile.
+	URL string `json:"url,omitempty"`
+	// IconURL is an optional URL of an icon displayed instead of the name.
+	IconURL string `json:"iconURL,omitempty"`
 }
 
 // Builder represents an email message with various fields such as subject, recipients, and content.
@@ -26,21 +156,82 @@ type Builder struct {
 	subject string
 	from    Address
 	replyTo *Address
+	sender  *Address
 	to      []string
 	cc      []string
 	bcc     []string
+	headers map[string][]string
 
-	textDirection  string
-	theme          string
-	usePremailer   bool
-	preheader      string
-	greeting       string
-	name           string
-	salutation     string
-	components     []Component
-	fallbacks      []*Action
-	fallbackFormat string
-	product        Product
+	messageID       string
+	messageIDDomain string
+	priority        Priority
+
+	unsubscribeURL string
+	unsubscribe    UnsubscribeOption
+	preferenceURL  string
+	preferenceText string
+	preference     PreferenceCenterOption
+	browserURL     string
+	browserText    string
+
+	textDirection   string
+	language        string
+	doctype         string
+	amp             bool
+	brandColor      string
+	fontFamily      string
+	bodyColor       string
+	backgroundColor string
+	noDarkMode      bool
+	noTextHeader    bool
+	theme           string
+	usePremailer    bool
+	preheader       string
+	greeting        string
+	greetingFormat  string
+	name            string
+	salutation      string
+	components      []Component
+	fallbacks       []*Action
+	fallbackFormat  string
+	product         Product
+	social          []SocialLink
+	copyrightSuffix string
+	noGreeting      bool
+	noSalutation    bool
+	signature       *Signature
+	noCopyright     bool
+	bare            bool
+	wrapColumns     int
+	strictAddress   bool
+	validateOnBuild bool
+	allowDuplicates bool
+	strictLinks     bool
+	linkSchemes     []string
+	linkParams      map[string]string
+	overrideParams  bool
+	rewriteLink     func(link string) string
+	rewriteAll      bool
+	autoPlainText   bool
+	newlineMode     string
+	logger          *slog.Logger
+	warnOnClipping  bool
+	clipThreshold   int
+	clock           func() time.Time
+
+	fallbackPlacement string
+	premailerOptions  *premailer.Options
+
+	// fallbackGreeting and fallbackProductName are captured from the default Builder
+	// when the Builder is created, so later SetDefault calls do not affect it.
+	fallbackGreeting    string
+	fallbackProductName string
+
+	// greetingSet, salutationSet and fallbackFormatSet record whether the texts were set explicitly,
+	// so that Locale does not replace them with the localized defaults.
+	greetingSet       bool
+	salutationSet     bool
+	fallbackFormatSet bool
 }
 
 var defaultBuilder atomic.Pointer[Builder]
@@ -50,43 +241,114 @@ func init() {
 }
 
 func newDefaultBuilder() *Builder {
+	english := resolveLocale("en")
 	return &Builder{
 		textDirection: "ltr",
 		theme:         "default",
 		usePremailer:  true,
-		greeting:      "Hi",
-		salutation:    "Best regards",
+		greeting:      english.Greeting,
+		salutation:    english.Salutation,
 		product: Product{
-			Name:      "Go-Mailgen",
-			Link:      "https://github.com/akfaiz/go-mailgen",
-			Copyright: fmt.Sprintf("© %d Go-Mailgen. All rights reserved.", time.Now().Year()),
+			Name: "Go-Mailgen",
+			Link: "https://github.com/akfaiz/go-mailgen",
 		},
-		fallbackFormat: "If you're having trouble clicking the \"[ACTION]\" button, copy and paste the URL below into your web browser:",
+		copyrightSuffix:   "All rights reserved.",
+		newlineMode:       NewlineCompact,
+		fallbackPlacement: FallbackEnd,
+		fallbackFormat:    english.FallbackFormat,
 	}
 }
 
 func (b *Builder) clone() *Builder {
 	cloned := &Builder{
-		textDirection:  b.textDirection,
-		subject:        b.subject,
-		from:           b.from,
-		to:             append([]string{}, b.to...),
-		cc:             append([]string{}, b.cc...),
-		bcc:            append([]string{}, b.bcc...),
-		theme:          b.theme,
-		usePremailer:   b.usePremailer,
-		fallbackFormat: b.fallbackFormat,
-		preheader:      b.preheader,
-		greeting:       b.greeting,
-		name:           b.name,
-		salutation:     b.salutation,
-		fallbacks:      append([]*Action{}, b.fallbacks...),
-		components:     append([]Component{}, b.components...),
-		product:        b.product,
+		textDirection:   b.textDirection,
+		language:        b.language,
+		doctype:         b.doctype,
+		amp:             b.amp,
+		brandColor:      b.brandColor,
+		fontFamily:      b.fontFamily,
+		bodyColor:       b.bodyColor,
+		backgroundColor: b.backgroundColor,
+		noDarkMode:      b.noDarkMode,
+		noTextHeader:    b.noTextHeader,
+		subject:         b.subject,
+		unsubscribeURL:  b.unsubscribeURL,
+		unsubscribe:     b.unsubscribe,
+		preferenceURL:   b.preferenceURL,
+		preferenceText:  b.preferenceText,
+		preference:      b.preference,
+		browserURL:      b.browserURL,
+		browserText:     b.browserText,
+		messageID:       b.messageID,
+		messageIDDomain: b.messageIDDomain,
+		priority:        b.priority,
+		from:            b.from,
+		to:              append([]string{}, b.to...),
+		cc:              append([]string{}, b.cc...),
+		bcc:             append([]string{}, b.bcc...),
+		theme:           b.theme,
+		usePremailer:    b.usePremailer,
+		fallbackFormat:  b.fallbackFormat,
+		preheader:       b.preheader,
+		greeting:        b.greeting,
+		greetingFormat:  b.greetingFormat,
+		name:            b.name,
+		salutation:      b.salutation,
+		fallbacks:       append([]*Action{}, b.fallbacks...),
+		components:      append([]Component{}, b.components...),
+		product:         b.product,
+		social:          append([]SocialLink{}, b.social...),
+		copyrightSuffix: b.copyrightSuffix,
+		noGreeting:      b.noGreeting,
+		noSalutation:    b.noSalutation,
+		noCopyright:     b.noCopyright,
+		bare:            b.bare,
+		wrapColumns:     b.wrapColumns,
+		strictAddress:   b.strictAddress,
+		validateOnBuild: b.validateOnBuild,
+		allowDuplicates: b.allowDuplicates,
+		strictLinks:     b.strictLinks,
+		linkSchemes:     slices.Clone(b.linkSchemes),
+		linkParams:      maps.Clone(b.linkParams),
+		overrideParams:  b.overrideParams,
+		rewriteLink:     b.rewriteLink,
+		rewriteAll:      b.rewriteAll,
+		autoPlainText:   b.autoPlainText,
+		newlineMode:     b.newlineMode,
+		logger:          b.logger,
+		warnOnClipping:  b.warnOnClipping,
+		clipThreshold:   b.clipThreshold,
+		clock:           b.clock,
+
+		fallbackPlacement: b.fallbackPlacement,
+
+		fallbackGreeting:    stringOr(b.greeting, b.fallbackGreeting),
+		fallbackProductName: stringOr(b.product.Name, b.fallbackProductName),
+
+		greetingSet:       b.greetingSet,
+		salutationSet:     b.salutationSet,
+		fallbackFormatSet: b.fallbackFormatSet,
+	}
+	if b.headers != nil {
+		cloned.headers = make(map[string][]string, len(b.headers))
+		for key, values := range b.headers {
+			cloned.headers[key] = append([]string{}, values...)
+		}
+	}
+	if b.premailerOptions != nil {
+		options := *b.premailerOptions
+		cloned.premailerOptions = &options
 	}
 	if b.replyTo != nil {
 		cloned.replyTo = &Address{Name: b.replyTo.Name, Address: b.replyTo.Address}
 	}
+	if b.signature != nil {
+		signature := *b.signature
+		cloned.signature = &signature
+	}
+	if b.sender != nil {
+		cloned.sender = &Address{Name: b.sender.Name, Address: b.sender.Address}
+	}
 
 	return cloned
 }
@@ -157,7 +419,28 @@ func (b *Builder) ReplyTo(address string, name ...string) *Builder {
 	return b
 }
 
+// Sender sets the Sender email address for the email message, the mailbox that actually sends it when it
+// differs from From, e.g. when sending on behalf of a customer. It can include a name for the Sender address.
+// Some providers require it when From differs from the authenticated SMTP identity.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		From("jane@customer.com", "Jane Doe").
+//		Sender("notifications@example.com", "Example")
+func (b *Builder) Sender(address string, name ...string) *Builder {
+	addr := Address{
+		Address: address,
+	}
+	if len(name) > 0 {
+		addr.Name = name[0]
+	}
+	b.sender = &addr
+	return b
+}
+
 // To add a recipient's email address to the email message.
+// Duplicate addresses are removed in Build, see AllowDuplicateRecipients.
 func (b *Builder) To(to string, others ...string) *Builder {
 	values := b.filterRecipients(to, others...)
 	if len(values) == 0 {
@@ -177,13 +460,45 @@ func (b *Builder) filterRecipients(first string, others ...string) []string {
 	var filtered []string
 	for _, recipient := range values {
 		if recipient != "" {
-			filtered = append(filtered, recipient)
+			filtered = append(filtered, punycodeAddress(recipient))
 		}
 	}
 	return filtered
 }
 
+// punycodeAddress converts an internationalized domain of the address to its ASCII (punycode) form,
+// e.g. "user@münchen.de" becomes "user@xn--mnchen-3ya.de". The display name, if any, is preserved.
+func punycodeAddress(address string) string {
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return address
+	}
+	domain := address[at+1:]
+	suffix := ""
+	if strings.HasSuffix(domain, ">") {
+		domain, suffix = domain[:len(domain)-1], ">"
+	}
+	if isASCII(domain) {
+		return address
+	}
+	asciiDomain, err := idna.Lookup.ToASCII(domain)
+	if err != nil {
+		return address
+	}
+	return address[:at+1] + asciiDomain + suffix
+}
+
+func isASCII(s string) bool {
+	for i := range len(s) {
+		if s[i] > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
 // Cc adds a carbon copy (CC) recipient's email address to the email message.
+// Duplicate addresses are removed in Build, see AllowDuplicateRecipients.
 func (b *Builder) Cc(cc string, others ...string) *Builder {
 	values := b.filterRecipients(cc, others...)
 	if len(values) == 0 {
@@ -194,6 +509,13 @@ func (b *Builder) Cc(cc string, others ...string) *Builder {
 }
 
 // Bcc adds a blind carbon copy (BCC) recipient's email address to the email message.
+// Duplicate addresses are removed in Build, see AllowDuplicateRecipients.
+//
+// Recipients set on the default Builder are kept when a message adds its own, e.g. to BCC
+// every email to an archive mailbox:
+//
+//	mailgen.SetDefault(mailgen.New().Bcc("archive@example.com"))
+//	email := mailgen.New().Bcc("audit@example.com") // BCC: archive@example.com, audit@example.com
 func (b *Builder) Bcc(bcc string, others ...string) *Builder {
 	values := b.filterRecipients(bcc, others...)
 	if len(values) == 0 {
@@ -203,246 +525,1936 @@ func (b *Builder) Bcc(bcc string, others ...string) *Builder {
 	return b
 }
 
-// Theme sets the theme for the email message.
-// Built-in themes are "default" and "plain". Custom themes can be added via RegisterTheme.
-func (b *Builder) Theme(theme string) *Builder {
-	b.theme = theme
+// AllowDuplicateRecipients enables or disables duplicate To, Cc, and Bcc addresses.
+// By default, Build keeps only the first occurrence of each address, and an address in more than one list is
+// kept only in the first of To, Cc, and Bcc, so that it is not delivered twice. Addresses are compared
+// ignoring their display names and the case of their domain, e.g. "John <john@Example.com>" and
+// "john@example.com" are the same address.
+func (b *Builder) AllowDuplicateRecipients(allow bool) *Builder {
+	b.allowDuplicates = allow
 	return b
 }
 
-// UsePremailer enables or disables CSS inlining via go-premailer when generating HTML.
-// The default value is true.
-func (b *Builder) UsePremailer(enabled bool) *Builder {
-	b.usePremailer = enabled
+// recipients returns the To, Cc, and Bcc addresses, with duplicates removed unless AllowDuplicateRecipients
+// is enabled.
+func (b *Builder) recipients() (to, cc, bcc []string) {
+	if b.allowDuplicates {
+		return b.to, b.cc, b.bcc
+	}
+	seen := make(map[string]bool)
+	dedupe := func(addresses []string) []string {
+		deduped := make([]string, 0, len(addresses))
+		for _, address := range addresses {
+			key := recipientKey(address)
+			if !seen[key] {
+				seen[key] = true
+				deduped = append(deduped, address)
+			}
+		}
+		return deduped
+	}
+	return dedupe(b.to), dedupe(b.cc), dedupe(b.bcc)
+}
+
+// recipientKey returns the address of recipient with its domain lowercased, used to detect duplicates.
+func recipientKey(recipient string) string {
+	address := strings.TrimSpace(recipient)
+	if addr, err := mail.ParseAddress(recipient); err == nil {
+		address = addr.Address
+	}
+	if at := strings.LastIndex(address, "@"); at >= 0 {
+		return address[:at+1] + strings.ToLower(address[at+1:])
+	}
+	return address
+}
+
+// StrictAddresses enables or disables validation of the From, Reply-To, To, Cc, and Bcc addresses in Build.
+// When enabled, Build returns an error wrapping ErrInvalidAddress that names the offending field and value,
+// e.g. `invalid To address "john(at)example.com"`. Addresses are parsed with net/mail.ParseAddress.
+// Default is false, which accepts any address.
+func (b *Builder) StrictAddresses(strict bool) *Builder {
+	b.strictAddress = strict
 	return b
 }
 
-// TextDirection sets the text direction for the email message.
-// It can be "ltr" (left-to-right) or "rtl" (right-to-left).
-func (b *Builder) TextDirection(direction string) *Builder {
-	if direction != "ltr" && direction != "rtl" {
-		return b // Invalid direction, do nothing
+// ValidateOnBuild enables or disables calling Validate in Build, so that Build fails with ErrNoSubject,
+// ErrNoRecipients or an error wrapping ErrInvalidAddress instead of building an incomplete message.
+// Default is false, which builds messages without a subject or recipients, e.g. for previews.
+func (b *Builder) ValidateOnBuild(enabled bool) *Builder {
+	b.validateOnBuild = enabled
+	return b
+}
+
+// defaultLinkSchemes are the URL schemes allowed in action links unless set via Builder.AllowedLinkSchemes.
+var defaultLinkSchemes = []string{"http", "https", "mailto"}
+
+// AllowedLinkSchemes sets the URL schemes allowed in action links, e.g. "https" and "mailto".
+// The default is http, https, and mailto; calling it without schemes restores the default.
+// Links that cannot be parsed, have no scheme, or use a scheme that is not allowed, e.g. "javascript:",
+// are rendered as "#" by Build, or rejected if StrictLinks is enabled.
+func (b *Builder) AllowedLinkSchemes(schemes ...string) *Builder {
+	if len(schemes) == 0 {
+		b.linkSchemes = nil
+		return b
+	}
+	b.linkSchemes = make([]string, 0, len(schemes))
+	for _, scheme := range schemes {
+		b.linkSchemes = append(b.linkSchemes, strings.ToLower(strings.TrimSuffix(scheme, ":")))
 	}
-	b.textDirection = direction
 	return b
 }
 
-// FallbackFormat sets the fallback format for action buttons in the email message.
-// This format is used when the email client does not support HTML buttons.
+// StrictLinks enables or disables rejecting invalid action links in Build.
+// When enabled, Build returns an error wrapping ErrInvalidLink that names the offending link and action,
+// instead of rendering the link as "#". See AllowedLinkSchemes for the links that are considered valid.
+// Default is false.
+func (b *Builder) StrictLinks(strict bool) *Builder {
+	b.strictLinks = strict
+	return b
+}
+
+// LinkParams sets query parameters that Build appends to the http and https links of all actions,
+// e.g. UTM parameters for campaign tracking. The parameters are URL-encoded and added after the existing
+// query of the link. Parameters the link already has are left as they are, unless OverrideLinkParams is
+// enabled. Calling LinkParams again replaces the parameters, and calling it with none removes them.
 //
 // Example usage:
 //
 //	email := mailgen.New().
-//		FallbackFormat("If you're having trouble clicking the \"[ACTION]\" button, copy and paste the URL below into your web browser:")
-func (b *Builder) FallbackFormat(format string) *Builder {
-	if format == "" {
-		return b // No format provided, do nothing
+//		LinkParams(map[string]string{
+//			"utm_source":   "newsletter",
+//			"utm_campaign": "spring-sale",
+//		}).
+//		Action("Shop now", "https://example.com/shop?ref=email")
+func (b *Builder) LinkParams(params map[string]string) *Builder {
+	b.linkParams = make(map[string]string, len(params))
+	for key, value := range params {
+		if key == "" {
+			continue // Invalid parameter, do nothing
+		}
+		b.linkParams[key] = value
 	}
-	b.fallbackFormat = format
 	return b
 }
 
-// Preheader sets the preheader text for the email message.
-// The preheader is a short summary text that follows the subject line when an email is viewed in the inbox.
-// It is often used to provide additional context or a preview of the email content.
+// OverrideLinkParams enables or disables overwriting the parameters of action links that are also
+// set via LinkParams. Default is false, which leaves the existing values of the links as they are.
+func (b *Builder) OverrideLinkParams(override bool) *Builder {
+	b.overrideParams = override
+	return b
+}
+
+// RewriteLinks sets a function Build calls on the links of the email to transform them, e.g. to route
+// them through the click-tracking redirector of an email service provider. It is called on the links
+// of the actions, callouts and markdown lines, the shipment tracking links, the product link, the social
+// links, the "view in browser" link, and the unsubscribe and preference center links of the footer,
+// after LinkParams are added. The List-Unsubscribe header and the links written in RawHTML components are
+// not rewritten. Only http and https links are rewritten, unless RewriteAllLinks is enabled. Nil removes the function.
 //
-// Preheader is not displayed in the email body but is included in the email headers.
-func (b *Builder) Preheader(preheader string) *Builder {
-	b.preheader = preheader
+// Example usage:
+//
+//	email := mailgen.New().
+//		RewriteLinks(func(link string) string {
+//			return "https://track.example.com/click?u=" + url.QueryEscape(link)
+//		}).
+//		Action("Reset password", "https://example.com/reset")
+func (b *Builder) RewriteLinks(rewrite func(link string) string) *Builder {
+	b.rewriteLink = rewrite
 	return b
 }
 
-// Greeting sets the greeting line of the email message.
-// The default is "Hi".
-func (b *Builder) Greeting(greeting string) *Builder {
-	b.greeting = greeting
+// RewriteAllLinks enables or disables calling the RewriteLinks function on all non-empty links,
+// including mailto: and anchor links. Default is false, which only rewrites http and https links.
+func (b *Builder) RewriteAllLinks(enabled bool) *Builder {
+	b.rewriteAll = enabled
 	return b
 }
 
-// Name sets the name of the greeting line in the email message.
-// This is typically used to personalize the greeting with the recipient's name.
+// Header adds a custom header to the email message, such as "X-Campaign-ID" or "X-Mailer".
+// The key is canonicalized and calling Header multiple times with the same key appends the values.
 //
-// If not set, the greeting will be a generic "Hi".
-func (b *Builder) Name(name string) *Builder {
-	b.name = name
+// Example usage:
+//
+//	email := mailgen.New().
+//		Header("X-Campaign-ID", "spring-sale").
+//		Header("X-Tag", "promo").
+//		Header("X-Tag", "newsletter")
+func (b *Builder) Header(key, value string) *Builder {
+	key = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(key))
+	if key == "" {
+		return b
+	}
+	if b.headers == nil {
+		b.headers = make(map[string][]string)
+	}
+	b.headers[key] = append(b.headers[key], value)
 	return b
 }
 
-// Salutation sets the closing salutation of the email message.
-// Default is "Best regards".
-func (b *Builder) Salutation(salutation string) *Builder {
-	b.salutation = salutation
+// MessageID sets the Message-ID header of the email message, e.g. "order-123@example.com".
+// Angle brackets are added if missing. IDs without an "@" are ignored.
+// It is included in Message.Headers and takes precedence over MessageIDDomain.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		MessageID("order-123@example.com")
+func (b *Builder) MessageID(id string) *Builder {
+	id = strings.Trim(strings.TrimSpace(id), "<>")
+	if !strings.Contains(id, "@") {
+		return b // Invalid Message-ID, do nothing
+	}
+	b.messageID = "<" + id + ">"
 	return b
 }
 
-// Line adds a line of text to the email message.
-// If an action is set, it will be added to the outro lines; otherwise, it will be added to the intro lines.
-func (b *Builder) Line(text string) *Builder {
-	b.components = append(b.components, Line{Text: text})
+// MessageIDDomain sets the domain of the Message-ID generated for each built message, e.g. "<uuid@example.com>",
+// so the hostname of the server is not used by the mail client. It is ignored if MessageID is set.
+//
+// Example usage:
+//
+//	mailgen.SetDefault(mailgen.New().
+//		MessageIDDomain("mail.example.com"))
+func (b *Builder) MessageIDDomain(domain string) *Builder {
+	b.messageIDDomain = strings.Trim(strings.TrimSpace(domain), "@")
 	return b
 }
 
-// Linef adds a formatted line of text to the email message.
-// If an action is set, it will be added to the outro lines; otherwise, it will be added to the intro lines.
-func (b *Builder) Linef(format string, args ...interface{}) *Builder {
-	text := fmt.Sprintf(format, args...)
-	return b.Line(text)
+// Priority sets the priority of the email message. PriorityHigh and PriorityLow add the X-Priority,
+// Importance, and Priority headers to Message.Headers. The default value is PriorityNormal, which adds no headers.
+// Invalid values are ignored.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Priority(mailgen.PriorityHigh)
+func (b *Builder) Priority(priority Priority) *Builder {
+	switch priority {
+	case PriorityNormal, PriorityLow, PriorityHigh:
+		b.priority = priority
+	default:
+		// Invalid priority, do nothing
+	}
+	return b
 }
 
-// Action sets the action text and link for the email message.
-// It creates a button that the recipient can click to perform an action.
+// Unsubscribe sets the unsubscribe URL for the email message.
+// The URL is used for the RFC 2369 List-Unsubscribe header, and a https URL also enables the
+// RFC 8058 one-click List-Unsubscribe-Post header. Both headers are included in Message.Headers.
+//
+// The URL can be either a https or a mailto URL. An additional mailto address can be set via
+// UnsubscribeOption.Mailto, in which case both are listed in the List-Unsubscribe header.
 //
 // Example usage:
 //
 //	email := mailgen.New().
-//		Line("Click the button below to get started").
-//		Action("Get Started", "https://example.com/get-started")
-func (b *Builder) Action(text, link string, cfg ...Action) *Builder {
-	action := &Action{
-		Text:  text,
-		Link:  link,
-		Color: "#3869D4",
-	}
-	noFallback := false
-	if len(cfg) > 0 {
-		if cfg[0].Color != "" {
-			action.Color = cfg[0].Color
+//		Unsubscribe("https://example.com/unsubscribe?id=123", mailgen.UnsubscribeOption{
+//			Mailto:   "unsubscribe@example.com",
+//			ShowLink: true,
+//		})
+func (b *Builder) Unsubscribe(url string, opts ...UnsubscribeOption) *Builder {
+	b.unsubscribeURL = strings.TrimSpace(url)
+	b.unsubscribe = UnsubscribeOption{Text: "Unsubscribe"}
+	if len(opts) > 0 {
+		b.unsubscribe.Mailto = opts[0].Mailto
+		b.unsubscribe.ShowLink = opts[0].ShowLink
+		if opts[0].Text != "" {
+			b.unsubscribe.Text = opts[0].Text
 		}
-		noFallback = cfg[0].NoFallback
-	}
-	b.components = append(b.components, action)
-	if !noFallback {
-		b.fallbacks = append(b.fallbacks, action)
 	}
 	return b
 }
 
-// Product sets the product information for the email message.
-func (b *Builder) Product(product Product) *Builder {
-	defaultProduct := defaultBuilder.Load().product
-
-	b.product = product
-	if b.product.Name == "" {
-		b.product.Name = defaultProduct.Name
+// PreferenceCenter sets the URL of the preference center, where recipients can choose which emails they receive.
+// A link with the given text is rendered in the footer of the email, above the unsubscribe link if any.
+// If text is empty, "Manage preferences" is used.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		PreferenceCenter("https://example.com/preferences?id=123", "Email preferences",
+//			mailgen.PreferenceCenterOption{Header: true})
+func (b *Builder) PreferenceCenter(url, text string, opts ...PreferenceCenterOption) *Builder {
+	b.preferenceURL = strings.TrimSpace(url)
+	b.preferenceText = text
+	if b.preferenceText == "" {
+		b.preferenceText = "Manage preferences"
 	}
-	if b.product.Copyright == "" {
-		b.product.Copyright = fmt.Sprintf("© %d %s. All rights reserved.", time.Now().Year(), b.product.Name)
+	b.preference = PreferenceCenterOption{}
+	if len(opts) > 0 {
+		b.preference = opts[0]
 	}
-	b.product.Link = product.Link
 	return b
 }
 
-// Table sets a table to be included in the email message.
+// ViewInBrowser sets the URL of a hosted copy of the email, e.g. for heavy emails that some clients fail to display.
+// A small link is rendered above the header of the email, and a line with the URL at the top of the plain text.
+// If text is not given, "View this email in your browser" is used. An empty URL removes the link.
 //
 // Example usage:
 //
 //	email := mailgen.New().
-//		Table(mailgen.Table{
-//			Data: [][]mailgen.Entry{
-//			{
-//				{Key: "Name", Value: "John Doe"},
-//				{Key: "Email", Value: "john.doe@example.com"},
-//			},
-//			{
-//				{Key: "Name", Value: "Jane Smith"},
-//				{Key: "Email", Value: "jane.smith@example.com"},
-//			},
-//		},
-//		Columns: mailgen.Columns{
-//			CustomWidth: map[string]string{
-//				"Name":  "50%",
-//				"Email": "50%",
-//			},
-//			CustomAlign: map[string]string{
-//				"Name":  "left",
-//				"Email": "right",
-//			},
-//		},
-//	})
-func (b *Builder) Table(table Table) *Builder {
-	if len(table.Data) == 0 {
-		return b // No table to add
+//		ViewInBrowser("https://example.com/emails/123")
+func (b *Builder) ViewInBrowser(url string, text ...string) *Builder {
+	b.browserURL = strings.TrimSpace(url)
+	b.browserText = "View this email in your browser"
+	if len(text) > 0 && text[0] != "" {
+		b.browserText = text[0]
 	}
-
-	b.components = append(b.components, &table)
 	return b
 }
 
-// Build generates the final Message object with the HTML and plaintext content.
+// Logger sets the logger used to report the outcome of Build, including the subject,
+// the number of recipients and the elapsed time. The content of the email is never logged.
+// Failures are logged at the error level and successful builds at the debug level.
+// By default, nothing is logged.
 //
-// It processes all the components, actions, and other fields set in the Builder.
+// Example usage:
 //
-// Returns an error if there is an issue generating the HTML or plaintext content.
-func (b *Builder) Build() (Message, error) {
-	b.beforeBuild()
-	html, err := b.generateHTML()
-	if err != nil {
-		return nil, err
-	}
-	plainText, err := b.generatePlaintext()
-	if err != nil {
-		return nil, err
-	}
-	return &message{
-		subject:   b.subject,
-		from:      b.from,
-		replyTo:   b.replyTo,
-		to:        b.to,
-		cc:        b.cc,
-		bcc:       b.bcc,
-		html:      html,
-		plainText: plainText,
-	}, nil
+//	email := mailgen.New().
+//		Logger(slog.Default())
+func (b *Builder) Logger(logger *slog.Logger) *Builder {
+	b.logger = logger
+	return b
 }
 
-func (b *Builder) beforeBuild() {
-	for _, fallback := range b.fallbacks {
-		fallback.FallbackText = strings.ReplaceAll(b.fallbackFormat, "[ACTION]", fallback.Text)
-	}
+// DefaultClipThreshold is the HTML size in bytes above which Gmail clips messages,
+// hiding the rest of the content behind a "View entire message" link.
+const DefaultClipThreshold = 102 * 1024
+
+// WarnOnClipping enables or disables a warning when the HTML content built by Build is larger than
+// the clip threshold, DefaultClipThreshold unless set via ClipThreshold. The warning is logged at the
+// warn level to the Logger, or to slog.Default if no Logger is set, and does not fail the build.
+// The size is that of the final HTML, after the styles are inlined. Default is false.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		WarnOnClipping(true).
+//		ClipThreshold(80 * 1024)
+func (b *Builder) WarnOnClipping(enabled bool) *Builder {
+	b.warnOnClipping = enabled
+	return b
 }
 
-type templateData struct {
-	TextDirection  string
-	Preheader      string
-	Greeting       string
-	Salutation     string
-	ComponentsHTML []htmltemplate.HTML
-	ComponentsText []string
-	Fallbacks      []*Action
-	Product        Product
+// ClipThreshold sets the HTML size in bytes above which WarnOnClipping warns.
+// A value of 0 or less restores DefaultClipThreshold.
+func (b *Builder) ClipThreshold(bytes int) *Builder {
+	b.clipThreshold = max(bytes, 0)
+	return b
 }
 
-func (b *Builder) generateHTML() (string, error) {
-	theme := resolveTheme(b.theme)
-	tmpl := theme.HTML
+// Clock sets the function used to get the current time, e.g. the year of the generated copyright.
+// It is useful to pin the time in tests. Passing nil restores time.Now, which is the default.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Clock(func() time.Time { return time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC) })
+func (b *Builder) Clock(now func() time.Time) *Builder {
+	b.clock = now
+	return b
+}
 
-	var componentsHTML []htmltemplate.HTML
-	for _, comp := range b.components {
-		html, err := comp.HTML(tmpl)
-		if err != nil {
-			return "", err
-		}
-		componentsHTML = append(componentsHTML, htmltemplate.HTML(html)) //nolint:gosec // trusted HTML from templates
+func (b *Builder) now() time.Time {
+	if b.clock == nil {
+		return time.Now()
 	}
+	return b.clock()
+}
 
-	data := templateData{
-		TextDirection:  b.textDirection,
-		Preheader:      b.preheader,
-		Greeting:       b.greetingLine(),
-		Salutation:     b.salutation,
-		Product:        b.product,
-		ComponentsHTML: componentsHTML,
-		Fallbacks:      b.fallbacks,
-	}
-	var buf bytes.Buffer
+// PremailerOptions sets the options used to inline the CSS of the HTML output,
+// e.g. to remove class attributes or keep "!important" declarations.
+// Passing nil restores the default options. It has no effect when UsePremailer is disabled.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		PremailerOptions(premailer.NewOptions(premailer.WithKeepBangImportant(true)))
+func (b *Builder) PremailerOptions(options *premailer.Options) *Builder {
+	if options == nil {
+		b.premailerOptions = nil
+		return b
+	}
+	opts := *options
+	b.premailerOptions = &opts
+	return b
+}
+
+// Theme sets the theme for the email message.
+// Built-in themes are "default" and "plain". Custom themes can be added via RegisterTheme.
+func (b *Builder) Theme(theme string) *Builder {
+	b.theme = theme
+	return b
+}
+
+// UsePremailer enables or disables CSS inlining via go-premailer when generating HTML.
+// The default value is true.
+func (b *Builder) UsePremailer(enabled bool) *Builder {
+	b.usePremailer = enabled
+	return b
+}
+
+// InlineCSS enables or disables inlining the CSS of the HTML output. It is an alias of UsePremailer.
+// Disabling it is useful when the ESP inlines CSS itself, e.g. for AMP emails,
+// and speeds up generating large batches of emails. The default value is true.
+func (b *Builder) InlineCSS(enabled bool) *Builder {
+	return b.UsePremailer(enabled)
+}
+
+// Doctype sets the doctype declaration of the HTML output of the built-in themes,
+// e.g. DoctypeHTML5. The default is DoctypeXHTMLTransitional. Values that are not
+// a doctype declaration are ignored. Custom themes declare their own doctype.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Doctype(mailgen.DoctypeHTML5)
+func (b *Builder) Doctype(doctype string) *Builder {
+	doctype = strings.TrimSpace(doctype)
+	if !strings.HasPrefix(strings.ToLower(doctype), "<!doctype ") || !strings.HasSuffix(doctype, ">") {
+		return b // Invalid doctype, do nothing
+	}
+	b.doctype = doctype
+	return b
+}
+
+// AMP enables or disables rendering an AMP for Email variant of the default theme, returned by Message.AMPHTML.
+// It should be sent as the text/x-amp-html part of the email, between the plain text and HTML parts.
+// Images must use https URLs, and raw HTML is included as is, so it must be valid AMP markup.
+// The default value is false.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		AMP(true)
+func (b *Builder) AMP(enabled bool) *Builder {
+	b.amp = enabled
+	return b
+}
+
+// defaultBrandColor is the accent color of the built-in themes.
+const defaultBrandColor = "#3869D4"
+
+// hexColorRegex matches 3 or 6 digit hex color codes, e.g. "#FFF" or "#3869D4".
+var hexColorRegex = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// fontFamilyRegex matches CSS font stacks, e.g. `"Open Sans", Arial, sans-serif`.
+var fontFamilyRegex = regexp.MustCompile(`^[\w\s,"'-]+$`)
+
+// BrandColor sets the accent color of the email, e.g. "#E91E63". The built-in themes use it for links,
+// the product name in the header, and titles, and it is the default color of actions and callouts.
+// Values that are not 3 or 6 digit hex color codes are ignored. The default value is "#3869D4".
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		BrandColor("#E91E63")
+func (b *Builder) BrandColor(color string) *Builder {
+	color = strings.TrimSpace(color)
+	if !hexColorRegex.MatchString(color) {
+		return b // Invalid color, do nothing
+	}
+	b.brandColor = color
+	return b
+}
+
+// Font sets the font stack of the built-in themes, e.g. `"Open Sans", Arial, sans-serif`.
+// Values that are not a list of font names are ignored.
+// The default value is `"Nunito Sans", Helvetica, Arial, sans-serif`.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Font(`Georgia, "Times New Roman", serif`)
+func (b *Builder) Font(family string) *Builder {
+	family = strings.TrimSpace(family)
+	if !fontFamilyRegex.MatchString(family) {
+		return b // Invalid font family, do nothing
+	}
+	b.fontFamily = family
+	return b
+}
+
+// Colors sets the background colors of the built-in themes: body is the color of the content area of the email,
+// and background is the color around it. Values that are not 3 or 6 digit hex color codes, including empty values,
+// keep the current color. The default values are "#FFFFFF" and "#F2F4F6".
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Colors("#FFFDF8", "#F5F1E8")
+func (b *Builder) Colors(body, background string) *Builder {
+	if body = strings.TrimSpace(body); hexColorRegex.MatchString(body) {
+		b.bodyColor = body
+	}
+	if background = strings.TrimSpace(background); hexColorRegex.MatchString(background) {
+		b.backgroundColor = background
+	}
+	return b
+}
+
+// DarkMode enables or disables the dark mode styles of the built-in themes. When enabled, the HTML declares
+// support for the light and dark color schemes, and a @media (prefers-color-scheme: dark) block, which is kept
+// by premailer, switches to dark backgrounds, light text, and a lighter accent color for links in dark clients.
+// The default value is true.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		DarkMode(false)
+func (b *Builder) DarkMode(enabled bool) *Builder {
+	b.noDarkMode = !enabled
+	return b
+}
+
+// TextDirection sets the text direction for the email message.
+// It can be "ltr" (left-to-right) or "rtl" (right-to-left).
+func (b *Builder) TextDirection(direction string) *Builder {
+	if direction != "ltr" && direction != "rtl" {
+		return b // Invalid direction, do nothing
+	}
+	b.textDirection = direction
+	return b
+}
+
+// Language sets the language of the email content as a BCP 47 language tag, e.g. "en" or "pt-BR",
+// which is set as the lang attribute of the HTML document so that screen readers pronounce the text
+// correctly. Locale sets it too. An empty tag removes it, and invalid tags are ignored.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Language("de-AT")
+func (b *Builder) Language(tag string) *Builder {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag != "" && !languageTagRegex.MatchString(tag) {
+		return b // Invalid language tag, do nothing
+	}
+	b.language = tag
+	return b
+}
+
+// languageTagRegex matches the syntax of a BCP 47 language tag, e.g. "en" or "zh-Hant-TW".
+var languageTagRegex = regexp.MustCompile(`^[a-zA-Z]{1,8}(?:-[a-zA-Z0-9]{1,8})*$`)
+
+// FallbackFormat sets the fallback format for action buttons in the email message.
+// This format is used when the email client does not support HTML buttons.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		FallbackFormat("If you're having trouble clicking the \"[ACTION]\" button, copy and paste the URL below into your web browser:")
+func (b *Builder) FallbackFormat(format string) *Builder {
+	if format == "" {
+		return b // No format provided, do nothing
+	}
+	b.fallbackFormat = format
+	b.fallbackFormatSet = true
+	return b
+}
+
+// FallbackPlacement sets where the fallback texts of action buttons are rendered in the HTML output.
+// It can be FallbackEnd ("end") to group them at the end of the email, or FallbackAfterAction
+// ("after-action") to render each fallback immediately after its button, which helps when buttons are far apart.
+// FallbackFooter ("footer") and FallbackInline ("inline") are accepted as aliases. Default is FallbackEnd.
+func (b *Builder) FallbackPlacement(placement string) *Builder {
+	switch placement {
+	case FallbackFooter:
+		placement = FallbackEnd
+	case FallbackInline:
+		placement = FallbackAfterAction
+	case FallbackEnd, FallbackAfterAction:
+	default:
+		return b // Invalid placement, do nothing
+	}
+	b.fallbackPlacement = placement
+	return b
+}
+
+// PlainTextNewlineMode sets how consecutive newlines are handled in the plain text output.
+// It can be NewlineCompact ("compact") to collapse them into a single blank line, NewlineSingle ("single")
+// to collapse them into a single newline, or NewlinePreserve ("preserve") to keep them as they are.
+// Default is NewlineCompact.
+func (b *Builder) PlainTextNewlineMode(mode string) *Builder {
+	if mode != NewlineCompact && mode != NewlineSingle && mode != NewlinePreserve {
+		return b // Invalid mode, do nothing
+	}
+	b.newlineMode = mode
+	return b
+}
+
+// PlainTextHeader enables or disables the header line at the top of the plain text output, which shows the
+// product name and link, e.g. "[Acme] (https://example.com)". The logo is not shown in the plain text.
+// The default value is true.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		PlainTextHeader(false)
+func (b *Builder) PlainTextHeader(enabled bool) *Builder {
+	b.noTextHeader = !enabled
+	return b
+}
+
+// Preheader sets the preheader text for the email message.
+// The preheader is a short summary text that follows the subject line when an email is viewed in the inbox.
+// It is often used to provide additional context or a preview of the email content.
+//
+// Preheader is not displayed in the email body but is included in the email headers.
+func (b *Builder) Preheader(preheader string) *Builder {
+	b.preheader = preheader
+	return b
+}
+
+// Locale sets the greeting, salutation and action fallback format to the localized defaults of a language,
+// e.g. "es" or "de-AT". The languages "en", "es", "de" and "fr" are built in, and more can be added via
+// RegisterLocale. Unknown languages fall back to English.
+//
+// The texts set via Greeting, Salutation and FallbackFormat are kept, whether they are set before or after
+// Locale or on the default Builder via SetDefault. Locale also sets the Language of the email to lang,
+// unless the language is unknown.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Locale("es").
+//		Name("Juan")
+func (b *Builder) Locale(lang string) *Builder {
+	locale, found := lookupLocale(lang)
+	b.fallbackGreeting = locale.Greeting
+	if !b.greetingSet {
+		b.greeting = locale.Greeting
+	}
+	if !b.salutationSet {
+		b.salutation = locale.Salutation
+	}
+	if !b.fallbackFormatSet {
+		b.fallbackFormat = locale.FallbackFormat
+	}
+	if !found {
+		return b
+	}
+	return b.Language(lang)
+}
+
+// GreetingFormat sets the format of the greeting line, including its punctuation, using the [GREETING] and
+// [NAME] placeholders, e.g. "[GREETING], [NAME]!". When no name is set, [NAME] and the space next to it are
+// removed. By default, the greeting line is "[GREETING] [NAME]," or "[NAME] [GREETING]," for right-to-left emails.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Greeting("Dear").
+//		Name("Dr. Smith").
+//		GreetingFormat("[GREETING] [NAME]:")
+func (b *Builder) GreetingFormat(format string) *Builder {
+	b.greetingFormat = format
+	return b
+}
+
+// Greeting sets the greeting line of the email message.
+// The default is "Hi".
+func (b *Builder) Greeting(greeting string) *Builder {
+	b.greeting = greeting
+	b.greetingSet = true
+	return b
+}
+
+// NoGreeting omits the greeting line from the HTML and plain text output, so that the email starts with its
+// first component, e.g. for notifications such as "Your export is ready.".
+func (b *Builder) NoGreeting() *Builder {
+	b.noGreeting = true
+	return b
+}
+
+// Name sets the name of the greeting line in the email message.
+// This is typically used to personalize the greeting with the recipient's name.
+//
+// If not set or only whitespace, the greeting will be a generic "Hi".
+func (b *Builder) Name(name string) *Builder {
+	b.name = strings.TrimSpace(name)
+	return b
+}
+
+// Salutation sets the closing salutation of the email message.
+// Default is "Best regards".
+func (b *Builder) Salutation(salutation string) *Builder {
+	b.salutation = salutation
+	b.salutationSet = true
+	return b
+}
+
+// Signature sets the sender's signature, rendered below the salutation instead of the product name:
+// the name, an optional title, and an optional photo in HTML, and the name and title lines in plain text.
+// Signatures without a name are ignored.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Salutation("Kind regards").
+//		Signature(mailgen.Signature{
+//			Name:     "Jane Doe",
+//			Title:    "Account Manager, Acme",
+//			ImageURL: "https://example.com/jane.png",
+//		})
+func (b *Builder) Signature(signature Signature) *Builder {
+	if signature.Name == "" {
+		return b // No signature to add
+	}
+	b.signature = &signature
+	return b
+}
+
+// NoSalutation omits the closing salutation and the product name below it from the HTML and plain text
+// output, e.g. for automated alerts that do not need a sign-off. A signature set via Signature is still rendered.
+func (b *Builder) NoSalutation() *Builder {
+	b.noSalutation = true
+	return b
+}
+
+// Bare strips the email message down to its body.
+// The greeting, salutation, and copyright are omitted, and the plaintext output
+// contains only the body lines without the theme's header and footer.
+//
+// It is intended for machine-consumed emails, such as webhook notifications delivered via email.
+func (b *Builder) Bare() *Builder {
+	b.noGreeting = true
+	b.noSalutation = true
+	b.noCopyright = true
+	b.bare = true
+	return b
+}
+
+// WrapText hard-wraps the plain text output at a word boundary so that lines are at most columns wide.
+// Explicit newlines are preserved, URLs and other long words are never broken, and tables are not wrapped.
+// A value of 78 is a common choice. The default is 0, which disables wrapping.
+func (b *Builder) WrapText(columns int) *Builder {
+	if columns < 0 {
+		columns = 0
+	}
+	b.wrapColumns = columns
+	return b
+}
+
+// Line adds a line of text to the email message.
+// Lines are rendered in the order they are added along with the other components;
+// use Outro for closing remarks rendered with smaller, muted text.
+func (b *Builder) Line(text string) *Builder {
+	b.components = append(b.components, Line{Text: text})
+	return b
+}
+
+// PrependLine adds a line of text before the other components of the email message,
+// e.g. an urgent banner added after the content has been composed.
+func (b *Builder) PrependLine(text string) *Builder {
+	return b.InsertComponent(0, Line{Text: text})
+}
+
+// InsertComponent inserts c into the components of the email message at index, so that it is rendered
+// before the component currently at that position. An index below 0 inserts c first, and an index past
+// the last component appends it.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Line("Your order has shipped.").
+//		InsertComponent(0, &mailgen.Callout{Text: "Delivery may be delayed due to the holidays."})
+func (b *Builder) InsertComponent(index int, c Component) *Builder {
+	if c == nil {
+		return b // No component to insert
+	}
+	index = max(0, min(index, len(b.components)))
+	b.components = slices.Insert(b.components, index, c)
+	return b
+}
+
+// When calls fn with the Builder if cond is true, so that optional content can be added
+// without breaking the method chain. A nil fn is ignored.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Line("Your order has shipped.").
+//		When(order.Gift, func(b *mailgen.Builder) {
+//			b.Line("It is wrapped as a gift.")
+//		}).
+//		Action("Track order", trackingURL)
+func (b *Builder) When(cond bool, fn func(b *Builder)) *Builder {
+	if cond && fn != nil {
+		fn(b)
+	}
+	return b
+}
+
+// Unless calls fn with the Builder if cond is false. It is the opposite of When.
+func (b *Builder) Unless(cond bool, fn func(b *Builder)) *Builder {
+	return b.When(!cond, fn)
+}
+
+// Markdown adds a line of text with inline markdown to the email message.
+// Bold (**text**), italic (*text* or _text_), and links ([text](url)) are rendered as HTML,
+// and the markup is stripped in the plain text output, e.g. links become "text (url)".
+//
+// The text is HTML-escaped before conversion, and only http, https, and mailto links are rendered.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Markdown("Your order **#1234** has shipped. [Track it](https://example.com/track) anytime.")
+func (b *Builder) Markdown(text string) *Builder {
+	b.components = append(b.components, Line{Text: text, Markdown: true})
+	return b
+}
+
+// Outro adds a closing line to the email message, e.g. "If you did not request this, no further action is required.",
+// rendered with smaller, muted text. Outro lines are rendered in the order they are added, so add them after the
+// actions.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Line("Click the button below to reset your password").
+//		Action("Reset Password", "https://example.com/reset-password").
+//		Outro("If you did not request a password reset, no further action is required.")
+func (b *Builder) Outro(text string) *Builder {
+	b.components = append(b.components, Line{Text: text, Outro: true})
+	return b
+}
+
+// Linef adds a formatted line of text to the email message, like Line.
+func (b *Builder) Linef(format string, args ...interface{}) *Builder {
+	text := fmt.Sprintf(format, args...)
+	return b.Line(text)
+}
+
+// Action sets the action text and link for the email message.
+// It creates a button that the recipient can click to perform an action.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Line("Click the button below to get started").
+//		Action("Get Started", "https://example.com/get-started")
+func (b *Builder) Action(text, link string, cfg ...Action) *Builder {
+	action := newAction(text, link, cfg...)
+	b.components = append(b.components, action)
+	if len(cfg) == 0 || !cfg[0].NoFallback {
+		b.fallbacks = append(b.fallbacks, action)
+	}
+	return b
+}
+
+// Actions adds a group of action buttons rendered side by side on a single row.
+// Each action has its own fallback text unless NoFallback is set, and is rendered
+// on its own line in the plain text output.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Line("Will you attend the meetup?").
+//		Actions(
+//			mailgen.Action{Text: "Yes", Link: "https://example.com/rsvp?answer=yes"},
+//			mailgen.Action{Text: "No", Link: "https://example.com/rsvp?answer=no", Style: "secondary"},
+//		)
+func (b *Builder) Actions(actions ...Action) *Builder {
+	if len(actions) == 0 {
+		return b // No actions to add
+	}
+	group := &ActionGroup{}
+	for _, cfg := range actions {
+		action := newAction(cfg.Text, cfg.Link, cfg)
+		group.Actions = append(group.Actions, action)
+		if !cfg.NoFallback {
+			b.fallbacks = append(b.fallbacks, action)
+		}
+	}
+	b.components = append(b.components, group)
+	return b
+}
+
+// MailtoConfig configures the mailto action added via Builder.MailtoAction.
+type MailtoConfig struct {
+	// Subject is the prefilled subject of the reply.
+	Subject string
+	// Body is the prefilled body of the reply.
+	Body string
+	// Color is hex color code for the button, e.g. "#3869D4". Default is the brand color of the email.
+	Color string
+	// Style is the style of the button: "primary" (solid) or "secondary" (outlined). Default is "primary".
+	Style string
+}
+
+// MailtoAction adds a button that opens a new email to the address in the recipient's mail client,
+// with an optional prefilled subject and body. The plain text shows the address.
+// Mailto actions have no fallback text, as the link cannot be pasted into a web browser.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Line("Reply to this email to confirm your attendance.").
+//		MailtoAction("Confirm Attendance", "events@example.com", mailgen.MailtoConfig{
+//			Subject: "Confirm attendance",
+//			Body:    "I will attend the event.",
+//		})
+func (b *Builder) MailtoAction(text, address string, cfg ...MailtoConfig) *Builder {
+	var config MailtoConfig
+	if len(cfg) > 0 {
+		config = cfg[0]
+	}
+	action := newAction(text, mailtoLink(address, config.Subject, config.Body), Action{
+		Color:             config.Color,
+		Style:             config.Style,
+		NoFallback:        true,
+		PlainTextOverride: text + " (" + address + ")",
+	})
+	b.components = append(b.components, action)
+	return b
+}
+
+// mailtoLink builds a mailto URL with the subject and body percent-encoded as described in RFC 6068.
+func mailtoLink(address, subject, body string) string {
+	var params []string
+	if subject != "" {
+		params = append(params, "subject="+mailtoEscape(subject))
+	}
+	if body != "" {
+		params = append(params, "body="+mailtoEscape(body))
+	}
+	link := "mailto:" + address
+	if len(params) > 0 {
+		link += "?" + strings.Join(params, "&")
+	}
+	return link
+}
+
+func mailtoEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func newAction(text, link string, cfg ...Action) *Action {
+	action := &Action{
+		Text: text,
+		Link: link,
+	}
+	if len(cfg) > 0 {
+		action.Color = cfg[0].Color
+		action.Style = cfg[0].Style
+		action.Icon = cfg[0].Icon
+		action.FullWidth = cfg[0].FullWidth
+		action.NoFallback = cfg[0].NoFallback
+		action.PlainTextOverride = cfg[0].PlainTextOverride
+	}
+	return action
+}
+
+// Image adds an inline image to the email message, such as a banner or hero image.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Image(mailgen.Image{
+//			Src:   "https://example.com/banner.png",
+//			Alt:   "Spring sale",
+//			Width: "570",
+//		})
+//
+// To embed an image attached to the email instead of linking it, set CID to its Content-ID:
+//
+//	email := mailgen.New().
+//		Image(mailgen.Image{CID: "logo", Alt: "Logo"})
+func (b *Builder) Image(image Image) *Builder {
+	if image.Src == "" && image.CID == "" {
+		return b // No image to add
+	}
+	b.components = append(b.components, &image)
+	return b
+}
+
+// Title adds a prominent heading to the email message, e.g. "Your receipt", where it is placed
+// among the other components. It is rendered in upper case in the plain text.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Title("Your receipt").
+//		Line("Thanks for your purchase.")
+func (b *Builder) Title(text string) *Builder {
+	if text == "" {
+		return b // No title to add
+	}
+	b.components = append(b.components, &Title{Text: text})
+	return b
+}
+
+// Section adds a sub-heading to the email message to structure long emails, e.g. monthly summaries.
+// It is smaller than a Title and is underlined with "=" in the plain text.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Section("Usage").
+//		Line("You sent 1,204 emails this month.").
+//		Section("Billing").
+//		Line("Your next invoice is due on March 1.")
+func (b *Builder) Section(text string) *Builder {
+	if text == "" {
+		return b // No section to add
+	}
+	b.components = append(b.components, &Section{Text: text})
+	return b
+}
+
+// Divider adds a horizontal rule to the email message to separate sections.
+// Themes may render it as a rule or as whitespace; the built-in "plain" theme uses whitespace.
+func (b *Builder) Divider() *Builder {
+	b.components = append(b.components, &Divider{})
+	return b
+}
+
+// Coupon adds a promo code displayed in a dashed box to the email message.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Line("Thanks for your order! Here is a discount for your next purchase:").
+//		Coupon("SAVE10", mailgen.CouponConfig{Description: "10% off your next order"})
+func (b *Builder) Coupon(code string, cfg ...CouponConfig) *Builder {
+	if code == "" {
+		return b // No code to add
+	}
+	coupon := &Coupon{Code: code}
+	if len(cfg) > 0 {
+		coupon.Description = cfg[0].Description
+	}
+	b.components = append(b.components, coupon)
+	return b
+}
+
+// Shipping adds a highlighted block with the estimated delivery date, carrier, and tracking number
+// of a shipment to the email message. The tracking number links to TrackingURL when set.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Line("Good news! Your order is on its way.").
+//		Shipping(mailgen.ShippingConfig{
+//			Carrier:           "UPS",
+//			TrackingNumber:    "1Z999AA10123456784",
+//			TrackingURL:       "https://www.ups.com/track?tracknum=1Z999AA10123456784",
+//			EstimatedDelivery: time.Date(2024, time.January, 9, 0, 0, 0, 0, time.UTC),
+//		})
+func (b *Builder) Shipping(cfg ShippingConfig) *Builder {
+	shipping := &Shipping{
+		Carrier:        cfg.Carrier,
+		TrackingNumber: cfg.TrackingNumber,
+		TrackingURL:    cfg.TrackingURL,
+	}
+	if !cfg.EstimatedDelivery.IsZero() {
+		layout := cfg.DateFormat
+		if layout == "" {
+			layout = "Monday, Jan 2"
+		}
+		shipping.EstimatedDelivery = cfg.EstimatedDelivery.Format(layout)
+	}
+	if *shipping == (Shipping{}) {
+		return b // No shipping details to add
+	}
+	b.components = append(b.components, shipping)
+	return b
+}
+
+// RawHTML adds custom HTML to the email message. The HTML is inserted as is, without escaping,
+// so it must come from a trusted source. It has no plain text representation;
+// combine it with AutoPlainText to derive the plain text from the generated HTML.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		RawHTML(`<p>Hello <b>world</b></p>`).
+//		AutoPlainText()
+func (b *Builder) RawHTML(html string) *Builder {
+	if html == "" {
+		return b // No HTML to add
+	}
+	b.components = append(b.components, &RawHTML{Content: html})
+	return b
+}
+
+// AutoPlainText derives the plain text output from the generated HTML using HTMLToText,
+// instead of rendering it from the components. This guarantees a non-empty plain text part
+// for HTML-first emails, e.g. ones composed with RawHTML.
+func (b *Builder) AutoPlainText() *Builder {
+	b.autoPlainText = true
+	return b
+}
+
+// List adds a bulleted list to the email message.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Line("Here is what you can do next:").
+//		List([]string{"Complete your profile", "Invite your team"})
+func (b *Builder) List(items []string) *Builder {
+	if len(items) == 0 {
+		return b // No items to add
+	}
+	b.components = append(b.components, &List{Items: append([]string{}, items...)})
+	return b
+}
+
+// OrderedList adds a numbered list to the email message.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Line("To get started:").
+//		OrderedList([]string{"Download the app", "Sign in", "Connect your account"})
+func (b *Builder) OrderedList(items []string) *Builder {
+	if len(items) == 0 {
+		return b // No items to add
+	}
+	b.components = append(b.components, &List{Items: append([]string{}, items...), Ordered: true})
+	return b
+}
+
+// Callout adds a highlighted box of text to the email message.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Callout(mailgen.Callout{
+//			Text:     "Your subscription expires in 3 days.",
+//			Link:     "https://example.com/billing",
+//			LinkText: "Renew now",
+//		})
+func (b *Builder) Callout(callout Callout) *Builder {
+	b.components = append(b.components, &callout)
+	return b
+}
+
+// Alert adds a colored, bordered box of text to the email message, e.g. for security and status notices.
+// The level is one of AlertInfo, AlertSuccess, AlertWarning, or AlertDanger; unknown levels are rendered as
+// AlertInfo. In the plain text, the text is prefixed with the level, e.g. "[WARNING] Your password was changed".
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Alert(mailgen.AlertWarning, "Your password was changed").
+//		Line("If you did not make this change, please contact support immediately.")
+func (b *Builder) Alert(level, text string) *Builder {
+	if text == "" {
+		return b // No alert to add
+	}
+	b.components = append(b.components, &Alert{Text: text, Level: level})
+	return b
+}
+
+// SecurityNotice prepends a warning callout to the email message advising the recipient
+// to contact support if they did not request the email, e.g. for password reset emails.
+//
+// The default text can be overridden by passing a custom text.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Line("Click the button below to reset your password").
+//		Action("Reset Password", "https://example.com/reset-password").
+//		SecurityNotice("https://example.com/support")
+func (b *Builder) SecurityNotice(contactURL string, text ...string) *Builder {
+	notice := &Callout{
+		Text:     "If you didn't request this, your account may be compromised. Please contact support immediately.",
+		Link:     contactURL,
+		LinkText: "Contact support",
+		Color:    "#FF6136",
+	}
+	if len(text) > 0 && text[0] != "" {
+		notice.Text = text[0]
+	}
+	return b.InsertComponent(0, notice)
+}
+
+// DigestItem adds a titled block built by fn, rendered as a separate card.
+// Multiple digest items stack, which is useful for digest emails summarizing several updates.
+// The plain text shows each item under its underlined title.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Line("Here is what happened today.").
+//		DigestItem("New comment", func(item *mailgen.Builder) {
+//			item.Line("Jane commented on your post.").
+//				Action("View comment", "https://example.com/comments/1")
+//		}).
+//		DigestItem("New follower", func(item *mailgen.Builder) {
+//			item.Line("John started following you.")
+//		})
+func (b *Builder) DigestItem(title string, fn func(*Builder)) *Builder {
+	item := &Builder{}
+	if fn != nil {
+		fn(item)
+	}
+	b.components = append(b.components, &DigestItem{Title: title, Components: item.components})
+	b.fallbacks = append(b.fallbacks, item.fallbacks...)
+	return b
+}
+
+// Product sets the product information for the email message.
+// If Copyright is not set, it is generated from the current year (see Clock), the product name,
+// and the suffix set via CopyrightSuffix.
+func (b *Builder) Product(product Product) *Builder {
+	b.product = product
+	if b.product.Name == "" {
+		b.product.Name = b.fallbackProductName
+	}
+	b.product.Link = product.Link
+	return b
+}
+
+// Social sets the social links displayed as a centered row below the copyright in the footer.
+// Links without a URL are skipped. In plain text, each link is rendered as "Name: URL".
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Social([]mailgen.SocialLink{
+//			{Name: "Twitter", URL: "https://twitter.com/example"},
+//			{Name: "LinkedIn", URL: "https://linkedin.com/company/example", IconURL: "https://example.com/linkedin.png"},
+//		})
+func (b *Builder) Social(links []SocialLink) *Builder {
+	b.social = nil
+	for _, link := range links {
+		if link.URL != "" {
+			b.social = append(b.social, link)
+		}
+	}
+	return b
+}
+
+// CopyrightSuffix sets the suffix appended to the copyright generated when Product.Copyright is not set.
+// Default is "All rights reserved.". An empty suffix omits it.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Product(mailgen.Product{Name: "Go-Mailgen"}).
+//		CopyrightSuffix("Alle Rechte vorbehalten.")
+func (b *Builder) CopyrightSuffix(suffix string) *Builder {
+	b.copyrightSuffix = suffix
+	return b
+}
+
+// Table sets a table to be included in the email message.
+//
+// Example usage:
+//
+//	email := mailgen.New().
+//		Table(mailgen.Table{
+//			Data: [][]mailgen.Entry{
+//			{
+//				{Key: "Name", Value: "John Doe"},
+//				{Key: "Email", Value: "john.doe@example.com"},
+//			},
+//			{
+//				{Key: "Name", Value: "Jane Smith"},
+//				{Key: "Email", Value: "jane.smith@example.com"},
+//			},
+//		},
+//		Columns: mailgen.Columns{
+//			CustomWidth: map[string]string{
+//				"Name":  "50%",
+//				"Email": "50%",
+//			},
+//			CustomAlign: map[string]string{
+//				"Name":  "left",
+//				"Email": "right",
+//			},
+//		},
+//	})
+func (b *Builder) Table(table Table) *Builder {
+	if len(table.Data) == 0 {
+		return b // No table to add
+	}
+
+	b.components = append(b.components, &table)
+	return b
+}
+
+// Build generates the final Message object with the HTML and plaintext content.
+//
+// It processes all the components, actions, and other fields set in the Builder.
+//
+// Returns an error if there is an issue generating the HTML or plaintext content.
+func (b *Builder) Build() (Message, error) {
+	if b.logger == nil {
+		return b.build()
+	}
+	start := time.Now()
+	msg, err := b.build()
+	to, cc, bcc := b.recipients()
+	attrs := []any{
+		slog.String("subject", b.subject),
+		slog.Int("recipients", len(to)+len(cc)+len(bcc)),
+		slog.Duration("elapsed", time.Since(start)),
+	}
+	if err != nil {
+		b.logger.Error("mailgen: failed to build message", append(attrs, slog.Any("error", err))...)
+		return nil, err
+	}
+	b.logger.Debug("mailgen: built message", attrs...)
+	return msg, nil
+}
+
+func (b *Builder) build() (Message, error) {
+	if b.validateOnBuild {
+		if err := b.Validate(); err != nil {
+			return nil, err
+		}
+	} else if err := b.validateAddresses(); err != nil {
+		return nil, err
+	}
+	prepared, err := b.prepare()
+	if err != nil {
+		return nil, err
+	}
+	html, plainText, err := prepared.renderBodies()
+	if err != nil {
+		return nil, err
+	}
+	ampHTML, err := prepared.generateAMPHTML()
+	if err != nil {
+		return nil, err
+	}
+	headers, err := b.messageHeaders()
+	if err != nil {
+		return nil, err
+	}
+	b.checkClipping(html)
+	to, cc, bcc := b.recipients()
+	return &message{
+		subject:   b.subject,
+		from:      b.from,
+		replyTo:   b.replyTo,
+		sender:    b.sender,
+		to:        to,
+		cc:        cc,
+		bcc:       bcc,
+		headers:   headers,
+		cids:      b.contentIDs(),
+		html:      html,
+		plainText: plainText,
+		ampHTML:   ampHTML,
+		priority:  b.priority,
+	}, nil
+}
+
+// Render generates only the HTML and plaintext content of the email, the same as the HTML and PlainText
+// of the Message returned by Build. The addresses, headers and AMP content are not processed, so it is
+// a lighter alternative to Build when another system handles the addressing and only needs the bodies.
+//
+// Returns an error if there is an issue generating the HTML or plaintext content.
+//
+// Example usage:
+//
+//	html, text, err := email.Render()
+//	if err != nil {
+//		return err
+//	}
+func (b *Builder) Render() (string, string, error) {
+	prepared, err := b.prepare()
+	if err != nil {
+		return "", "", err
+	}
+	return prepared.renderBodies()
+}
+
+// prepare returns the Builder the email content is rendered with: a copy whose action links are sanitized,
+// tagged with the LinkParams, and rewritten if RewriteLinks is set. The actions are copied, so the components
+// of the Builder, which may be shared with other builders via SetDefault, are never modified, and building
+// again starts from the original links.
+func (b *Builder) prepare() (*Builder, error) {
+	prepared := b.withCopiedActions()
+	if err := prepared.sanitizeLinks(); err != nil {
+		return nil, err
+	}
+	prepared.addLinkParams()
+	prepared.beforeBuild()
+	prepared.rewriteLinks()
+	return prepared, nil
+}
+
+// withCopiedActions returns a copy of the Builder with copies of its actions, action groups and digest items,
+// which can be modified without affecting the Builder.
+func (b *Builder) withCopiedActions() *Builder {
+	copied := b.clone()
+	actions := make(map[*Action]*Action)
+	copied.components = copyActions(b.components, actions)
+	for i, fallback := range copied.fallbacks {
+		action, ok := actions[fallback]
+		if !ok {
+			a := *fallback
+			action = &a
+		}
+		copied.fallbacks[i] = action
+	}
+	return copied
+}
+
+// copyActions returns the components with their actions, action groups and digest items copied,
+// recording the copy of each action in actions.
+func copyActions(components []Component, actions map[*Action]*Action) []Component {
+	copied := make([]Component, 0, len(components))
+	for _, component := range components {
+		switch c := component.(type) {
+		case *Action:
+			action := *c
+			actions[c] = &action
+			component = &action
+		case *ActionGroup:
+			group := &ActionGroup{Actions: make([]*Action, 0, len(c.Actions))}
+			for _, a := range c.Actions {
+				action := *a
+				actions[a] = &action
+				group.Actions = append(group.Actions, &action)
+			}
+			component = group
+		case *DigestItem:
+			item := *c
+			item.Components = copyActions(c.Components, actions)
+			component = &item
+		}
+		copied = append(copied, component)
+	}
+	return copied
+}
+
+func (b *Builder) renderBodies() (string, string, error) {
+	html, err := b.generateHTML()
+	if err != nil {
+		return "", "", err
+	}
+	var plainText string
+	if b.autoPlainText {
+		plainText, err = b.htmlPlaintext(html)
+	} else {
+		plainText, err = b.generatePlaintext()
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return html, plainText, nil
+}
+
+// checkClipping logs a warning if WarnOnClipping is enabled and html is larger than the clip threshold.
+func (b *Builder) checkClipping(html string) {
+	threshold := b.clipThreshold
+	if threshold == 0 {
+		threshold = DefaultClipThreshold
+	}
+	if !b.warnOnClipping || len(html) <= threshold {
+		return
+	}
+	logger := b.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Warn("mailgen: HTML content may be clipped by email clients",
+		slog.String("subject", b.subject),
+		slog.Int("size", len(html)),
+		slog.Int("threshold", threshold),
+	)
+}
+
+// Validate checks that the email message is ready to be sent. It returns ErrNoSubject if the subject is empty,
+// ErrNoRecipients if there are no To, Cc, or Bcc addresses, and an error wrapping ErrInvalidAddress if any
+// address cannot be parsed, regardless of StrictAddresses. Errors can be matched with errors.Is.
+//
+// Build does not call Validate unless ValidateOnBuild is enabled, so incomplete messages can still be built,
+// e.g. for previews.
+//
+// Example usage:
+//
+//	if err := email.Validate(); errors.Is(err, mailgen.ErrNoRecipients) {
+//		return http.StatusBadRequest
+//	}
+func (b *Builder) Validate() error {
+	if strings.TrimSpace(b.subject) == "" {
+		return ErrNoSubject
+	}
+	if len(b.to)+len(b.cc)+len(b.bcc) == 0 {
+		return ErrNoRecipients
+	}
+	return b.checkAddresses()
+}
+
+func (b *Builder) validateAddresses() error {
+	if !b.strictAddress {
+		return nil
+	}
+	return b.checkAddresses()
+}
+
+func (b *Builder) checkAddresses() error {
+	if b.from.Address != "" {
+		if err := validateAddress("From", b.from.Address); err != nil {
+			return err
+		}
+	}
+	if b.replyTo != nil {
+		if err := validateAddress("Reply-To", b.replyTo.Address); err != nil {
+			return err
+		}
+	}
+	if b.sender != nil {
+		if err := validateAddress("Sender", b.sender.Address); err != nil {
+			return err
+		}
+	}
+	recipients := []struct {
+		field     string
+		addresses []string
+	}{
+		{"To", b.to},
+		{"Cc", b.cc},
+		{"Bcc", b.bcc},
+	}
+	for _, r := range recipients {
+		for _, address := range r.addresses {
+			if err := validateAddress(r.field, address); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateAddress(field, address string) error {
+	if _, err := mail.ParseAddress(address); err != nil {
+		return fmt.Errorf("%w: invalid %s address %q", ErrInvalidAddress, field, address)
+	}
+	return nil
+}
+
+// sanitizeLinks replaces the invalid links of the actions with "#", or returns an error wrapping
+// ErrInvalidLink for the first one if StrictLinks is enabled. Empty links are left as they are.
+func (b *Builder) sanitizeLinks() error {
+	for _, action := range b.actions() {
+		if action.Link == "" || b.allowedLink(action.Link) {
+			continue
+		}
+		if b.strictLinks {
+			return fmt.Errorf("%w: %q of action %q", ErrInvalidLink, action.Link, action.Text)
+		}
+		action.Link = "#"
+	}
+	return nil
+}
+
+// addLinkParams adds the LinkParams to the http and https links of the actions.
+func (b *Builder) addLinkParams() {
+	if len(b.linkParams) == 0 {
+		return
+	}
+	for _, action := range b.actions() {
+		action.Link = b.withLinkParams(action.Link)
+	}
+}
 
-	if err := tmpl.ExecuteTemplate(&buf, "index.html", data); err != nil {
+// withLinkParams returns link with the LinkParams added to its query. The existing query is kept as it is,
+// except for the parameters that are overridden.
+func (b *Builder) withLinkParams(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return link
+	}
+	existing := u.Query()
+	var pairs []string
+	if u.RawQuery != "" {
+		for _, pair := range strings.Split(u.RawQuery, "&") {
+			key, _, _ := strings.Cut(pair, "=")
+			if key, err := url.QueryUnescape(key); err == nil && b.overrideParams {
+				if _, ok := b.linkParams[key]; ok {
+					continue
+				}
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+	keys := slices.Sorted(maps.Keys(b.linkParams))
+	for _, key := range keys {
+		if existing.Has(key) && !b.overrideParams {
+			continue
+		}
+		pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(b.linkParams[key]))
+	}
+	u.RawQuery = strings.Join(pairs, "&")
+	return u.String()
+}
+
+// rewriteLinks rewrites the links of the Builder with the RewriteLinks function, if it is set.
+// It is called on the copy returned by prepare, so the components it modifies are copied first,
+// except for the actions, which prepare has already copied.
+func (b *Builder) rewriteLinks() {
+	if b.rewriteLink == nil {
+		return
+	}
+	b.rewriteComponents(b.components)
+	b.product.Link = b.rewrite(b.product.Link)
+	for i := range b.social {
+		b.social[i].URL = b.rewrite(b.social[i].URL)
+	}
+	b.unsubscribeURL = b.rewrite(b.unsubscribeURL)
+	b.preferenceURL = b.rewrite(b.preferenceURL)
+	b.browserURL = b.rewrite(b.browserURL)
+}
+
+// rewriteComponents rewrites the links of the components in place, replacing the callouts,
+// shipping details, and markdown lines with rewritten copies.
+func (b *Builder) rewriteComponents(components []Component) {
+	for i, component := range components {
+		switch c := component.(type) {
+		case *Action:
+			c.Link = b.rewrite(c.Link)
+		case *ActionGroup:
+			for _, action := range c.Actions {
+				action.Link = b.rewrite(action.Link)
+			}
+		case *Callout:
+			callout := *c
+			callout.Link = b.rewrite(callout.Link)
+			components[i] = &callout
+		case Callout:
+			c.Link = b.rewrite(c.Link)
+			components[i] = c
+		case *Shipping:
+			shipping := *c
+			shipping.TrackingURL = b.rewrite(shipping.TrackingURL)
+			components[i] = &shipping
+		case Shipping:
+			c.TrackingURL = b.rewrite(c.TrackingURL)
+			components[i] = c
+		case *Line:
+			line := *c
+			line.Text = b.rewriteMarkdownLinks(line)
+			components[i] = &line
+		case Line:
+			c.Text = b.rewriteMarkdownLinks(c)
+			components[i] = c
+		case *DigestItem:
+			b.rewriteComponents(c.Components)
+		}
+	}
+}
+
+// rewriteMarkdownLinks returns the text of the line with the links rewritten if it is markdown.
+func (b *Builder) rewriteMarkdownLinks(line Line) string {
+	if !line.Markdown {
+		return line.Text
+	}
+	return markdownLinkRegex.ReplaceAllStringFunc(line.Text, func(match string) string {
+		parts := markdownLinkRegex.FindStringSubmatch(match)
+		return "[" + parts[1] + "](" + b.rewrite(parts[2]) + ")"
+	})
+}
+
+// rewrite returns link rewritten by the RewriteLinks function. Empty links are left as they are,
+// as are the links that are not http or https unless RewriteAllLinks is enabled.
+func (b *Builder) rewrite(link string) string {
+	if link == "" {
+		return link
+	}
+	if !b.rewriteAll {
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return link
+		}
+	}
+	return b.rewriteLink(link)
+}
+
+// actions returns the actions of the email, including those of action groups and digest items.
+func (b *Builder) actions() []*Action {
+	var actions []*Action
+	for _, component := range flattenComponents(b.components) {
+		switch c := component.(type) {
+		case *Action:
+			actions = append(actions, c)
+		case *ActionGroup:
+			actions = append(actions, c.Actions...)
+		}
+	}
+	return actions
+}
+
+func (b *Builder) allowedLink(link string) bool {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Scheme == "" {
+		return false
+	}
+	schemes := b.linkSchemes
+	if schemes == nil {
+		schemes = defaultLinkSchemes
+	}
+	return slices.Contains(schemes, u.Scheme)
+}
+
+func (b *Builder) contentIDs() []string {
+	var cids []string
+	for _, component := range flattenComponents(b.components) {
+		var cid string
+		switch c := component.(type) {
+		case *Image:
+			cid = c.CID
+		case Image:
+			cid = c.CID
+		}
+		if cid != "" && !slices.Contains(cids, cid) {
+			cids = append(cids, cid)
+		}
+	}
+	return cids
+}
+
+func (b *Builder) messageHeaders() (map[string][]string, error) {
+	messageID, err := b.messageIDHeader()
+	if err != nil {
+		return nil, err
+	}
+	listUnsubscribe := b.listUnsubscribe()
+	listManage := b.listManage()
+	priority := priorityHeaders[b.priority]
+	if messageID == "" && priority == nil && listUnsubscribe == "" && listManage == "" {
+		return b.headers, nil
+	}
+	headers := make(map[string][]string, len(b.headers)+len(priority)+4) //nolint:mnd // Message-ID and list headers
+	for key, values := range b.headers {
+		headers[key] = values
+	}
+	if messageID != "" {
+		headers["Message-Id"] = []string{messageID}
+	}
+	for key, value := range priority {
+		headers[key] = []string{value}
+	}
+	if listUnsubscribe != "" {
+		headers["List-Unsubscribe"] = []string{listUnsubscribe}
+		if strings.Contains(listUnsubscribe, "<https://") {
+			headers["List-Unsubscribe-Post"] = []string{"List-Unsubscribe=One-Click"}
+		}
+	}
+	if listManage != "" {
+		headers["List-Manage"] = []string{listManage}
+	}
+	return headers, nil
+}
+
+// priorityHeaders are the headers added for each priority, as understood by common email clients.
+var priorityHeaders = map[Priority]map[string]string{
+	PriorityHigh: {"X-Priority": "1 (Highest)", "Importance": "High", "Priority": "urgent"},
+	PriorityLow:  {"X-Priority": "5 (Lowest)", "Importance": "Low", "Priority": "non-urgent"},
+}
+
+// messageIDHeader returns the Message-ID set via MessageID, or generates one with the domain set via
+// MessageIDDomain. It returns "" if neither is set.
+func (b *Builder) messageIDHeader() (string, error) {
+	if b.messageID != "" || b.messageIDDomain == "" {
+		return b.messageID, nil
+	}
+	id, err := randomUUID()
+	if err != nil {
+		return "", err
+	}
+	return "<" + id + "@" + b.messageIDDomain + ">", nil
+}
+
+// randomUUID returns a random (version 4) UUID as defined in RFC 9562.
+func randomUUID() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", err
+	}
+	uuid[6] = (uuid[6] & 0x0f) | 0x40 //nolint:mnd // version 4
+	uuid[8] = (uuid[8] & 0x3f) | 0x80 //nolint:mnd // RFC 9562 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
+
+func (b *Builder) listManage() string {
+	if !b.preference.Header || b.preferenceURL == "" {
+		return ""
+	}
+	return "<" + b.preferenceURL + ">"
+}
+
+func (b *Builder) listUnsubscribe() string {
+	var urls []string
+	if b.unsubscribeURL != "" {
+		urls = append(urls, "<"+b.unsubscribeURL+">")
+	}
+	if mailto := strings.TrimSpace(b.unsubscribe.Mailto); mailto != "" {
+		if !strings.HasPrefix(strings.ToLower(mailto), "mailto:") {
+			mailto = "mailto:" + mailto
+		}
+		urls = append(urls, "<"+mailto+">")
+	}
+	return strings.Join(urls, ", ")
+}
+
+// WriteHTML writes the HTML content of the email message to w, as returned by Message.HTML.
+// It is useful to stream emails to files or HTTP responses without building a Message.
+//
+// The HTML is rendered into an internal buffer before being written, as inlining the CSS
+// and cleaning up the output require the complete document.
+func (b *Builder) WriteHTML(w io.Writer) error {
+	prepared, err := b.prepare()
+	if err != nil {
+		return err
+	}
+	html, err := prepared.generateHTML()
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, html)
+	return err
+}
+
+// WritePlainText writes the plain text content of the email message to w, as returned by Message.PlainText.
+//
+// The plain text is streamed to w as the template is rendered, unless AutoPlainText is enabled,
+// in which case it is derived from the complete HTML first.
+func (b *Builder) WritePlainText(w io.Writer) error {
+	prepared, err := b.prepare()
+	if err != nil {
+		return err
+	}
+	if !prepared.autoPlainText {
+		return prepared.writePlaintext(w)
+	}
+	html, err := prepared.generateHTML()
+	if err != nil {
+		return err
+	}
+	text, err := prepared.htmlPlaintext(html)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, text)
+	return err
+}
+
+// ContentHTML renders only the components of the email message (lines, actions, tables, etc.)
+// using the selected theme, without the surrounding document, greeting, salutation, or footer.
+//
+// The result can be embedded in a parent template without being escaped again.
+// CSS is not inlined, so the parent template is responsible for styling the theme classes.
+//
+// Example usage:
+//
+//	content, err := mailgen.New().
+//		Line("Your order has shipped.").
+//		Action("Track Order", "https://example.com/track").
+//		ContentHTML()
+//	err = parent.Execute(w, map[string]any{"EmailContent": content})
+func (b *Builder) ContentHTML() (htmltemplate.HTML, error) {
+	prepared, err := b.prepare()
+	if err != nil {
+		return "", err
+	}
+	componentsHTML, err := prepared.renderComponentsHTML(resolveTheme(prepared.theme).HTML)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, html := range componentsHTML {
+		sb.WriteString(string(html))
+	}
+	return htmltemplate.HTML(cleanEmailHTML(sb.String())), nil //nolint:gosec // trusted HTML from templates
+}
+
+func (b *Builder) beforeBuild() {
+	for _, fallback := range b.fallbacks {
+		fallback.FallbackText = strings.ReplaceAll(b.fallbackFormat, "[ACTION]", fallback.Text)
+	}
+}
+
+type templateData struct {
+	Doctype          htmltemplate.HTML
+	BrandColor       string
+	FontFamily       htmltemplate.CSS
+	BodyColor        string
+	BackgroundColor  string
+	DarkMode         bool
+	TextDirection    string
+	Language         string
+	Preheader        string
+	PreheaderPadding htmltemplate.HTML
+	TextHeader       bool
+	Greeting         string
+	GreetingSuffix   string
+	Salutation       string
+	Signature        *Signature
+	ComponentsHTML   []htmltemplate.HTML
+	ComponentsText   []string
+	Fallbacks        []*Action
+	Product          Product
+	Social           []SocialLink
+	UnsubscribeURL   string
+	UnsubscribeText  string
+	PreferenceURL    string
+	PreferenceText   string
+	BrowserURL       string
+	BrowserText      string
+}
+
+// preheaderPreviewLength is the number of characters the preheader is padded to,
+// which covers the longest inbox previews of common email clients.
+const preheaderPreviewLength = 150
+
+// preheaderPadding returns the invisible characters that fill the inbox preview after the preheader.
+// Each "&#847;&zwnj;&nbsp;" sequence renders as a blank character that clients do not collapse.
+func preheaderPadding(preheader string) htmltemplate.HTML {
+	if preheader == "" {
+		return ""
+	}
+	count := preheaderPreviewLength - utf8.RuneCountInString(preheader)
+	if count <= 0 {
+		return ""
+	}
+	return htmltemplate.HTML(strings.Repeat("&#847;&zwnj;&nbsp;", count)) //nolint:gosec // constant entities
+}
+
+func (b *Builder) generateHTML() (string, error) {
+	theme := resolveTheme(b.theme)
+	tmpl := theme.HTML
+
+	if !b.usePremailer || !isBuiltinHTMLTemplate(tmpl) {
+		return b.renderHTML(tmpl)
+	}
+	return b.generateCachedHTML(tmpl)
+}
+
+// generateAMPHTML renders the AMP for Email variant of the email message, or returns "" if AMP is disabled.
+// AMP requires the CSS to be in the head of the document, so it is never inlined.
+func (b *Builder) generateAMPHTML() (string, error) {
+	if !b.amp {
+		return "", nil
+	}
+	raw, err := b.renderRawHTML(templates.AMPHTMLTmpl)
+	if err != nil {
+		return "", err
+	}
+	return cleanEmailHTML(string(raw)), nil
+}
+
+func (b *Builder) renderHTML(tmpl *htmltemplate.Template) (string, error) {
+	raw, err := b.renderRawHTML(tmpl)
+	if err != nil {
 		return "", err
 	}
 	if !b.usePremailer {
-		return cleanEmailHTML(buf.String()), nil
+		return cleanEmailHTML(string(raw)), nil
 	}
-	prem, err := premailer.NewPremailerFromBytes(buf.Bytes(), premailer.NewOptions())
+	prem, err := premailer.NewPremailerFromBytes(raw, b.premailerOpts())
 	if err != nil {
 		return "", err
 	}
@@ -453,6 +2465,145 @@ func (b *Builder) generateHTML() (string, error) {
 	return cleanEmailHTML(html), nil
 }
 
+// renderRawHTML renders the HTML of the email message without inlining its CSS.
+func (b *Builder) renderRawHTML(tmpl *htmltemplate.Template) ([]byte, error) {
+	componentsHTML, err := b.renderComponentsHTML(tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	data := b.templateData(componentsHTML)
+	var buf bytes.Buffer
+
+	if err := tmpl.ExecuteTemplate(&buf, "index.html", data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (b *Builder) premailerOpts() *premailer.Options {
+	if b.premailerOptions == nil {
+		return premailer.NewOptions()
+	}
+	options := *b.premailerOptions
+	return &options
+}
+
+func (b *Builder) templateData(componentsHTML []htmltemplate.HTML) templateData {
+	data := templateData{
+		Doctype:          htmltemplate.HTML(stringOr(b.doctype, DoctypeXHTMLTransitional)), //nolint:gosec // validated doctype
+		BrandColor:       b.brandColor,
+		FontFamily:       htmltemplate.CSS(b.fontFamily), //nolint:gosec // validated font family
+		BodyColor:        b.bodyColor,
+		BackgroundColor:  b.backgroundColor,
+		DarkMode:         !b.noDarkMode,
+		TextDirection:    b.textDirection,
+		Language:         b.language,
+		Preheader:        b.preheader,
+		PreheaderPadding: preheaderPadding(b.preheader),
+		Greeting:         b.greetingLine(),
+		GreetingSuffix:   b.greetingSuffix(),
+		Salutation:       b.salutationLine(),
+		Signature:        b.signature,
+		Product:          b.productData(),
+		Social:           b.social,
+		ComponentsHTML:   componentsHTML,
+		Fallbacks:        b.footerFallbacks(),
+	}
+	data.UnsubscribeURL, data.UnsubscribeText = b.unsubscribeLink()
+	data.PreferenceURL, data.PreferenceText = b.preferenceLink()
+	data.BrowserURL, data.BrowserText = b.browserLink()
+	return data
+}
+
+func (b *Builder) renderComponentsHTML(tmpl *htmltemplate.Template) ([]htmltemplate.HTML, error) {
+	var componentsHTML []htmltemplate.HTML
+	for i, comp := range b.components {
+		html, err := b.withContext(comp).HTML(tmpl)
+		if err != nil {
+			return nil, componentError(i, comp, err)
+		}
+		componentsHTML = append(componentsHTML, htmltemplate.HTML(html)) //nolint:gosec // trusted HTML from templates
+		if b.fallbackPlacement != FallbackAfterAction {
+			continue
+		}
+		subcopy, err := b.renderSubcopyHTML(tmpl, comp)
+		if err != nil {
+			return nil, componentError(i, comp, err)
+		}
+		componentsHTML = append(componentsHTML, subcopy...)
+	}
+	return componentsHTML, nil
+}
+
+// renderContext returns the context the components of the email are rendered in.
+func (b *Builder) renderContext() RenderContext {
+	return RenderContext{
+		TextDirection: b.textDirection,
+		WrapColumns:   b.wrapColumns,
+		Theme:         b.theme,
+		BrandColor:    stringOr(b.brandColor, defaultBrandColor),
+	}
+}
+
+// withContext returns comp prepared for the render context of the email if it is a ContextualComponent.
+func (b *Builder) withContext(comp Component) Component {
+	if c, ok := comp.(ContextualComponent); ok {
+		return c.WithRenderContext(b.renderContext())
+	}
+	return comp
+}
+
+// flattenComponents returns the components along with the components nested in digest items.
+func flattenComponents(components []Component) []Component {
+	var flat []Component
+	for _, comp := range components {
+		flat = append(flat, comp)
+		if item, ok := comp.(*DigestItem); ok {
+			flat = append(flat, flattenComponents(item.Components)...)
+		}
+	}
+	return flat
+}
+
+// componentError wraps err with the index and type of the component that failed to render,
+// e.g. "component[2] (*mailgen.Action): template: ...".
+func componentError(index int, comp Component, err error) error {
+	return fmt.Errorf("component[%d] (%T): %w", index, comp, err)
+}
+
+// renderSubcopyHTML renders the fallbacks of the actions in comp, if any.
+func (b *Builder) renderSubcopyHTML(tmpl *htmltemplate.Template, comp Component) ([]htmltemplate.HTML, error) {
+	var actions []*Action
+	for _, nested := range flattenComponents([]Component{comp}) {
+		switch c := nested.(type) {
+		case *Action:
+			actions = append(actions, c)
+		case *ActionGroup:
+			actions = append(actions, c.Actions...)
+		}
+	}
+	var subcopies []htmltemplate.HTML
+	for _, action := range actions {
+		if !slices.Contains(b.fallbacks, action) {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, "subcopy", action); err != nil {
+			return nil, err
+		}
+		subcopies = append(subcopies, htmltemplate.HTML(buf.String())) //nolint:gosec // trusted HTML from templates
+	}
+	return subcopies, nil
+}
+
+func (b *Builder) footerFallbacks() []*Action {
+	if b.fallbackPlacement == FallbackAfterAction {
+		return nil
+	}
+	return b.fallbacks
+}
+
 func cleanEmailHTML(input string) string {
 	// Remove spaces and newlines between HTML tags
 	reBetweenTags := regexp.MustCompile(`>\s+<`)
@@ -476,49 +2627,286 @@ func cleanEmailHTML(input string) string {
 }
 
 func (b *Builder) generatePlaintext() (string, error) {
+	var sb strings.Builder
+	if err := b.writePlaintext(&sb); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// writePlaintext renders the plain text content of the email message from the theme template,
+// cleaning it up as it is written to w.
+func (b *Builder) writePlaintext(w io.Writer) error {
 	theme := resolveTheme(b.theme)
 
 	var componentsText []string
-	for _, comp := range b.components {
-		text, err := comp.PlainText()
+	for i, comp := range b.components {
+		text, err := b.withContext(comp).PlainText()
 		if err != nil {
-			return "", err
+			return componentError(i, comp, err)
+		}
+		if b.wrapColumns > 0 && !isTable(comp) {
+			text = wrapText(text, b.wrapColumns)
 		}
 		componentsText = append(componentsText, text)
 	}
+	cleaner := &textCleaner{w: w, newlineMode: b.newlineMode}
+	if b.bare {
+		if _, err := io.WriteString(cleaner, strings.Join(componentsText, "\n\n")); err != nil {
+			return err
+		}
+		return cleaner.Flush()
+	}
 
 	data := templateData{
 		Greeting:       b.greetingLine(),
+		GreetingSuffix: b.greetingSuffix(),
 		Preheader:      b.preheader,
-		Salutation:     b.salutation,
-		Product:        b.product,
+		TextHeader:     !b.noTextHeader,
+		Salutation:     b.salutationLine(),
+		Signature:      b.signature,
+		Product:        b.productData(),
+		Social:         b.social,
 		ComponentsText: componentsText,
 	}
-	var buf bytes.Buffer
-	if err := theme.PlainText.ExecuteTemplate(&buf, "index.txt", data); err != nil {
+	data.UnsubscribeURL, data.UnsubscribeText = b.unsubscribeLink()
+	data.PreferenceURL, data.PreferenceText = b.preferenceLink()
+	data.BrowserURL, data.BrowserText = b.browserLink()
+	if err := theme.PlainText.ExecuteTemplate(cleaner, "index.txt", data); err != nil {
+		return err
+	}
+	return cleaner.Flush()
+}
+
+func (b *Builder) htmlPlaintext(html string) (string, error) {
+	text, err := htmlToText(html)
+	if err != nil {
 		return "", err
 	}
-	text := buf.String()
+	text = cleanEmailText(text, b.newlineMode)
+	if b.wrapColumns > 0 {
+		text = wrapText(text, b.wrapColumns)
+	}
+	return text, nil
+}
 
-	return cleanEmailText(text), nil
+func isTable(comp Component) bool {
+	switch comp.(type) {
+	case *Table, Table:
+		return true
+	default:
+		return false
+	}
 }
 
-func cleanEmailText(input string) string {
-	clean := strings.TrimSpace(input)
-	re := regexp.MustCompile(`\n{3,}`)
-	clean = re.ReplaceAllString(clean, "\n\n")
-	return clean
+// wrapPrefixRegex matches the leading indentation and list marker of a line.
+var wrapPrefixRegex = regexp.MustCompile(`^\s*(?:[-*]\s+|\d+\.\s+)?`)
+
+// wrapText hard-wraps each line of text at a word boundary so that it fits within width columns.
+// Explicit newlines are preserved and words longer than width, such as URLs, are never broken.
+func wrapText(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = wrapLine(line, width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func wrapLine(line string, width int) string {
+	if utf8.RuneCountInString(line) <= width {
+		return line
+	}
+	prefix := wrapPrefixRegex.FindString(line)
+	indent := strings.Repeat(" ", utf8.RuneCountInString(prefix))
+
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	lineLen := len(indent)
+	lineStart := true
+	for _, word := range strings.Fields(line[len(prefix):]) {
+		wordLen := utf8.RuneCountInString(word)
+		if !lineStart && lineLen+1+wordLen > width {
+			sb.WriteString("\n" + indent)
+			lineLen = len(indent)
+			lineStart = true
+		}
+		if !lineStart {
+			sb.WriteString(" ")
+			lineLen++
+		}
+		sb.WriteString(word)
+		lineLen += wordLen
+		lineStart = false
+	}
+	return sb.String()
+}
+
+var (
+	blankLinesRegex  = regexp.MustCompile(`\n{3,}`)
+	newlineRunsRegex = regexp.MustCompile(`\n(?:[ \t]*\n)+`)
+)
+
+func cleanEmailText(input, newlineMode string) string {
+	return collapseNewlines(strings.TrimSpace(input), newlineMode)
+}
+
+// collapseNewlines collapses the runs of blank lines in text according to the newline mode.
+func collapseNewlines(text, newlineMode string) string {
+	switch newlineMode {
+	case NewlinePreserve:
+		return text
+	case NewlineSingle:
+		return newlineRunsRegex.ReplaceAllString(text, "\n")
+	default:
+		return blankLinesRegex.ReplaceAllString(text, "\n\n")
+	}
+}
+
+// textCleaner is an io.Writer that cleans up the text written to it like cleanEmailText, while streaming
+// it to the underlying writer. Whitespace is held back until the text that follows it is written, so that
+// the trailing whitespace can be dropped. Flush must be called after the last write.
+type textCleaner struct {
+	w           io.Writer
+	newlineMode string
+	started     bool
+	space       []byte
+	partial     []byte
+}
+
+func (c *textCleaner) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(c.partial) > 0 {
+		p = append(c.partial, p...)
+		c.partial = nil
+	}
+	text := 0
+	for i := 0; i < len(p); {
+		if !utf8.FullRune(p[i:]) {
+			// Keep the start of a rune split across writes until the rest of it is written.
+			c.partial = append([]byte(nil), p[i:]...)
+			p = p[:i]
+			break
+		}
+		r, size := utf8.DecodeRune(p[i:])
+		if unicode.IsSpace(r) {
+			if err := c.writeText(p[text:i]); err != nil {
+				return 0, err
+			}
+			c.space = append(c.space, p[i:i+size]...)
+			text = i + size
+		}
+		i += size
+	}
+	if err := c.writeText(p[text:]); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Flush writes the incomplete rune held back, if any, and drops the trailing whitespace.
+func (c *textCleaner) Flush() error {
+	partial := c.partial
+	c.partial = nil
+	err := c.writeText(partial)
+	c.space = c.space[:0]
+	return err
+}
+
+// writeText writes text preceded by the whitespace held back, which is dropped at the start of the text.
+func (c *textCleaner) writeText(text []byte) error {
+	if len(text) == 0 {
+		return nil
+	}
+	if c.started && len(c.space) > 0 {
+		if _, err := io.WriteString(c.w, collapseNewlines(string(c.space), c.newlineMode)); err != nil {
+			return err
+		}
+	}
+	c.space = c.space[:0]
+	c.started = true
+	_, err := c.w.Write(text)
+	return err
 }
 
 func (b *Builder) greetingLine() string {
+	if b.noGreeting {
+		return ""
+	}
+	greeting := stringOr(b.greeting, b.fallbackGreeting)
+	if b.greetingFormat != "" {
+		return formatGreeting(b.greetingFormat, greeting, b.name)
+	}
 	if b.name != "" {
 		if b.textDirection == "rtl" {
-			return fmt.Sprintf("%s %s", b.name, b.greeting)
+			return fmt.Sprintf("%s %s", b.name, greeting)
 		}
-		return fmt.Sprintf("%s %s", b.greeting, b.name)
+		return fmt.Sprintf("%s %s", greeting, b.name)
+	}
+	return greeting
+}
+
+func (b *Builder) unsubscribeLink() (string, string) {
+	if !b.unsubscribe.ShowLink || b.unsubscribeURL == "" {
+		return "", ""
+	}
+	return b.unsubscribeURL, b.unsubscribe.Text
+}
+
+func (b *Builder) preferenceLink() (string, string) {
+	if b.preferenceURL == "" {
+		return "", ""
+	}
+	return b.preferenceURL, b.preferenceText
+}
+
+func (b *Builder) browserLink() (string, string) {
+	if b.browserURL == "" {
+		return "", ""
 	}
-	if b.greeting == "" {
-		return defaultBuilder.Load().greeting
+	return b.browserURL, b.browserText
+}
+
+// greetingSuffix returns the punctuation appended to the greeting line by the templates,
+// which is part of the format when GreetingFormat is set.
+func (b *Builder) greetingSuffix() string {
+	if b.greetingFormat != "" {
+		return ""
+	}
+	return ","
+}
+
+func formatGreeting(format, greeting, name string) string {
+	if name == "" {
+		format = strings.NewReplacer(" [NAME]", "", "[NAME] ", "", "[NAME]", "").Replace(format)
+	}
+	return strings.NewReplacer("[GREETING]", greeting, "[NAME]", name).Replace(format)
+}
+
+func (b *Builder) salutationLine() string {
+	if b.noSalutation {
+		return ""
+	}
+	return b.salutation
+}
+
+func copyrightYears(startYear, year int) string {
+	if startYear > 0 && startYear < year {
+		return fmt.Sprintf("%d–%d", startYear, year)
+	}
+	return strconv.Itoa(year)
+}
+
+func (b *Builder) productData() Product {
+	product := b.product
+	if b.noCopyright {
+		product.Copyright = ""
+		return product
+	}
+	if product.Copyright == "" {
+		product.Copyright = fmt.Sprintf("© %s %s.", copyrightYears(product.CopyrightStartYear, b.now().Year()), product.Name)
+		if suffix := strings.TrimSpace(b.copyrightSuffix); suffix != "" && !product.NoCopyrightSuffix {
+			product.Copyright += " " + suffix
+		}
 	}
-	return b.greeting
+	return product
 }
@@ -0,0 +1,52 @@
+package mailgen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextCleaner_EquivalentToCleanEmailText(t *testing.T) {
+	inputs := []string{
+		"",
+		" \n\t ",
+		"Hello",
+		"\n\n  Hello,\n\n\n\nWorld  \n \n\t\n!\n\n\n",
+		"Line one\n\n\nLine two\n  \n  \nLine three",
+		"Ünïcödé 👋　\n\n\n wörld ",
+		"Tabs\t\t\n\t\n\tand spaces   \n",
+		"Invalid \xff\xfe bytes\n\n\n\xf0\x9f",
+	}
+	modes := []string{NewlineCompact, NewlineSingle, NewlinePreserve}
+	for _, input := range inputs {
+		for _, mode := range modes {
+			want := cleanEmailText(input, mode)
+			for _, size := range []int{1, 2, 3, len(input) + 1} {
+				var sb strings.Builder
+				cleaner := &textCleaner{w: &sb, newlineMode: mode}
+				for chunk := range chunks(input, size) {
+					n, err := cleaner.Write([]byte(chunk))
+					require.NoError(t, err)
+					assert.Equal(t, len(chunk), n)
+				}
+				require.NoError(t, cleaner.Flush())
+				assert.Equal(t, want, sb.String(), "input %q, mode %q, chunk size %d", input, mode, size)
+			}
+		}
+	}
+}
+
+// chunks yields s split into strings of size bytes, splitting runes across chunks.
+func chunks(s string, size int) func(yield func(string) bool) {
+	return func(yield func(string) bool) {
+		for len(s) > size {
+			if !yield(s[:size]) {
+				return
+			}
+			s = s[size:]
+		}
+		yield(s)
+	}
+}
@@ -1,13 +1,23 @@
 package mailgen_test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	htmltemplate "html/template"
+	"log/slog"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/akfaiz/go-mailgen"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/vanng822/go-premailer/premailer"
 )
 
 type testCase struct {
@@ -83,6 +93,58 @@ func TestSetDefault(t *testing.T) {
 		assert.Equal(t, "Before Nil Test", msg2.Subject())
 	})
 
+	t.Run("greeting fallback is captured when the builder is created", func(t *testing.T) {
+		mailgen.SetDefault(originalDefault)
+		builder := mailgen.New().Greeting("")
+		cloned := mailgen.New()
+
+		mailgen.SetDefault(mailgen.New().Greeting("Howdy").Product(mailgen.Product{Name: "Later Product"}))
+		builder.Name("John").Product(mailgen.Product{Link: "https://example.com"})
+		cloned.Greeting("")
+
+		msg, err := builder.Build()
+		require.NoError(t, err)
+		assert.Contains(t, msg.PlainText(), "Hi John,", "Greeting should not depend on a later default")
+		assert.Contains(t, msg.PlainText(), "Go-Mailgen", "Product name should not depend on a later default")
+		assert.NotContains(t, msg.PlainText(), "Later Product")
+
+		msg, err = cloned.Build()
+		require.NoError(t, err)
+		assert.Contains(t, msg.PlainText(), "Hi,")
+		assert.NotContains(t, msg.PlainText(), "Howdy")
+
+		msg, err = mailgen.New().Greeting("").Build()
+		require.NoError(t, err)
+		assert.Contains(t, msg.PlainText(), "Howdy,", "New builders should use the current default")
+	})
+
+	t.Run("default theme is applied unless overridden", func(t *testing.T) {
+		mailgen.SetDefault(originalDefault)
+		plain, err := mailgen.New().Theme("plain").Line("Hello").Build()
+		require.NoError(t, err)
+
+		mailgen.SetDefault(mailgen.New().Theme("plain"))
+		defer mailgen.SetDefault(originalDefault)
+
+		msg, err := mailgen.New().Line("Hello").Build()
+		require.NoError(t, err)
+		assert.Equal(t, plain.HTML(), msg.HTML(), "New builders should use the default theme")
+
+		msg, err = mailgen.New().Theme("default").Line("Hello").Build()
+		require.NoError(t, err)
+		assert.NotEqual(t, plain.HTML(), msg.HTML(), "Theme should override the default theme")
+	})
+
+	t.Run("default recipients are merged with message recipients", func(t *testing.T) {
+		mailgen.SetDefault(originalDefault)
+		mailgen.SetDefault(mailgen.New().Bcc("archive@example.com"))
+		defer mailgen.SetDefault(originalDefault)
+
+		msg, err := mailgen.New().Bcc("audit@example.com", "archive@Example.com").Build()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"archive@example.com", "audit@example.com"}, msg.Bcc())
+	})
+
 	t.Run("new instances are independent after setting default", func(t *testing.T) {
 		customBuilder := mailgen.New()
 		customBuilder.Subject("Base Subject")
@@ -202,6 +264,19 @@ func TestBuilder_ReplyTo(t *testing.T) {
 				)
 			},
 		},
+		{
+			name: "set reply-to with a name containing special characters",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().ReplyTo("support@example.com", `Support, "Shop" Inc.`)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, `"Support, \"Shop\" Inc." <support@example.com>`, msg.ReplyToString())
+				addr, err := mail.ParseAddress(msg.ReplyToString())
+				require.NoError(t, err, "Reply-To should parse as a single address")
+				assert.Equal(t, `Support, "Shop" Inc.`, addr.Name)
+			},
+		},
 		{
 			name:        "not set reply-to",
 			builderFunc: mailgen.New,
@@ -216,6 +291,37 @@ func TestBuilder_ReplyTo(t *testing.T) {
 	}
 }
 
+func TestBuilder_Sender(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "set sender with name",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					From("jane@customer.com", "Jane Doe").
+					Sender("notifications@example.com", "Example")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, "Jane Doe <jane@customer.com>", msg.FromString(), "From should not be changed")
+				assert.Equal(t, &mailgen.Address{Name: "Example", Address: "notifications@example.com"}, msg.Sender())
+				assert.Equal(t, "Example <notifications@example.com>", msg.SenderString())
+			},
+		},
+		{
+			name:        "not set sender",
+			builderFunc: mailgen.New,
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Nil(t, msg.Sender())
+				assert.Empty(t, msg.SenderString())
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
 func TestBuilder_To(t *testing.T) {
 	testCases := []testCase{
 		{
@@ -265,6 +371,19 @@ func TestBuilder_To(t *testing.T) {
 	}
 }
 
+func TestBuilder_RecipientsPunycode(t *testing.T) {
+	msg, err := mailgen.New().
+		To("user@münchen.de", "Jürgen <jürgen@bücher.example>").
+		Cc("cc@example.com").
+		Bcc("bcc@例え.jp").
+		Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"user@xn--mnchen-3ya.de", "Jürgen <jürgen@xn--bcher-kva.example>"}, msg.To())
+	assert.Equal(t, []string{"cc@example.com"}, msg.Cc(), "ASCII domains should be unchanged")
+	assert.Equal(t, []string{"bcc@xn--r8jz45g.jp"}, msg.Bcc())
+}
+
 func TestBuilder_Cc(t *testing.T) {
 	testCases := []testCase{
 		{
@@ -347,6 +466,18 @@ func TestBuilder_Bcc(t *testing.T) {
 				assert.Empty(t, msg.Bcc(), "BCC should be empty when no recipients are set")
 			},
 		},
+		{
+			name: "duplicate BCCs are ignored",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Bcc("archive@example.com", "Archive <archive@EXAMPLE.com>").
+					Bcc("archive@example.com", "bcc4@example.com")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, []string{"archive@example.com", "bcc4@example.com"}, msg.Bcc())
+			},
+		},
 		{
 			name: "set empty BCC",
 			builderFunc: func() *mailgen.Builder {
@@ -363,22 +494,37 @@ func TestBuilder_Bcc(t *testing.T) {
 	}
 }
 
-func TestBuilder_Preheader(t *testing.T) {
+func TestBuilder_AllowDuplicateRecipients(t *testing.T) {
 	testCases := []testCase{
 		{
-			name: "set preheader",
+			name: "duplicates across lists are removed",
 			builderFunc: func() *mailgen.Builder {
-				return mailgen.New().Preheader("This is a preheader text")
+				return mailgen.New().
+					To("a@example.com").
+					To("A <a@EXAMPLE.com>", "b@example.com").
+					Cc("b@example.com", "c@example.com", "A@example.com").
+					Bcc("c@example.com", "d@example.com")
 			},
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				assert.Contains(t, msg.HTML(), "This is a preheader text", "HTML should contain the preheader text")
-				assert.Contains(
-					t,
-					msg.PlainText(),
-					"This is a preheader text",
-					"PlainText should contain the preheader text",
-				)
+				assert.Equal(t, []string{"a@example.com", "b@example.com"}, msg.To())
+				assert.Equal(t, []string{"c@example.com", "A@example.com"}, msg.Cc(),
+					"Local parts should be compared case-sensitively")
+				assert.Equal(t, []string{"d@example.com"}, msg.Bcc())
+			},
+		},
+		{
+			name: "duplicates are kept when allowed",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					AllowDuplicateRecipients(true).
+					To("a@example.com", "a@example.com").
+					Cc("a@example.com")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, []string{"a@example.com", "a@example.com"}, msg.To())
+				assert.Equal(t, []string{"a@example.com"}, msg.Cc())
 			},
 		},
 	}
@@ -387,149 +533,190 @@ func TestBuilder_Preheader(t *testing.T) {
 	}
 }
 
-func TestBuilder_Greeting(t *testing.T) {
+func TestBuilder_Header(t *testing.T) {
 	testCases := []testCase{
 		{
-			name: "set greeting",
+			name: "set single header",
 			builderFunc: func() *mailgen.Builder {
-				return mailgen.New().Greeting("Hi there")
-			},
-			expectError: false,
-			expectFunc: func(msg mailgen.Message) {
-				assert.Contains(t, msg.HTML(), "Hi there", "HTML should contain the greeting text")
-				assert.Contains(t, msg.PlainText(), "Hi there", "PlainText should contain the greeting text")
+				return mailgen.New().Header("X-Campaign-ID", "spring-sale")
 			},
-		},
-		{
-			name:        "not set greeting should use default",
-			builderFunc: mailgen.New,
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				assert.Contains(t, msg.HTML(), "Hi", "HTML should contain the default greeting text")
-				assert.Contains(t, msg.PlainText(), "Hi", "PlainText should contain the default greeting text")
+				assert.Equal(t, []string{"spring-sale"}, msg.Headers()["X-Campaign-Id"])
 			},
 		},
 		{
-			name: "set greeting with name",
+			name: "set multiple values for the same header",
 			builderFunc: func() *mailgen.Builder {
-				return mailgen.New().Greeting("Hello").Name("John Doe")
+				return mailgen.New().
+					Header("X-Tag", "promo").
+					Header("x-tag", "newsletter")
 			},
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				assert.Contains(t, msg.HTML(), "Hello John Doe", "HTML should contain the greeting with name")
-				assert.Contains(t, msg.PlainText(), "Hello John Doe", "PlainText should contain the greeting with name")
+				assert.Equal(t, []string{"promo", "newsletter"}, msg.Headers()["X-Tag"])
 			},
 		},
 		{
-			name: "set name without greeting",
+			name: "set empty header key",
 			builderFunc: func() *mailgen.Builder {
-				return mailgen.New().Name("Jane Doe")
+				return mailgen.New().Header(" ", "value")
 			},
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				assert.Contains(t, msg.HTML(), "Hi Jane Doe", "HTML should contain the default greeting with name")
-				assert.Contains(
-					t,
-					msg.PlainText(),
-					"Hi Jane Doe",
-					"PlainText should contain the default greeting with name",
-				)
+				assert.Empty(t, msg.Headers(), "Headers should be empty when an empty key is set")
 			},
 		},
 		{
-			name: "set empty greeting",
-			builderFunc: func() *mailgen.Builder {
-				return mailgen.New().Greeting("")
-			},
+			name:        "not set headers",
+			builderFunc: mailgen.New,
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				assert.Contains(t, msg.HTML(), "Hi", "HTML should contain the default greeting text")
-				assert.Contains(t, msg.PlainText(), "Hi", "PlainText should contain the default greeting text")
+				assert.Empty(t, msg.Headers())
 			},
 		},
 	}
 	for _, tc := range testCases {
 		tc.run(t)
 	}
+
+	t.Run("headers survive set default", func(t *testing.T) {
+		originalDefault := mailgen.New()
+		defer mailgen.SetDefault(originalDefault)
+
+		mailgen.SetDefault(mailgen.New().Header("X-Mailer", "go-mailgen"))
+
+		msg1, err := mailgen.New().Header("X-Priority", "1").Build()
+		require.NoError(t, err)
+		msg2, err := mailgen.New().Build()
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"go-mailgen"}, msg1.Headers()["X-Mailer"])
+		assert.Equal(t, []string{"1"}, msg1.Headers()["X-Priority"])
+		assert.Equal(t, []string{"go-mailgen"}, msg2.Headers()["X-Mailer"])
+		assert.NotContains(t, msg2.Headers(), "X-Priority", "Headers should not leak between builders")
+	})
 }
 
-func TestBuilder_Salutation(t *testing.T) {
+func TestBuilder_MessageID(t *testing.T) {
 	testCases := []testCase{
 		{
-			name: "set salutation",
+			name:        "no Message-ID by default",
+			builderFunc: mailgen.New,
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.Headers(), "Message-Id")
+			},
+		},
+		{
+			name: "explicit Message-ID",
 			builderFunc: func() *mailgen.Builder {
-				return mailgen.New().Salutation("Kind regards")
+				return mailgen.New().MessageID("order-123@example.com")
 			},
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				assert.Contains(t, msg.HTML(), "Kind regards", "HTML should contain the salutation text")
-				assert.Contains(t, msg.PlainText(), "Kind regards", "PlainText should contain the salutation text")
+				assert.Equal(t, []string{"<order-123@example.com>"}, msg.Headers()["Message-Id"])
 			},
 		},
 		{
-			name:        "not set salutation should use default",
-			builderFunc: mailgen.New,
+			name: "explicit Message-ID takes precedence over domain",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().MessageIDDomain("mail.example.com").MessageID("<order-123@example.com>")
+			},
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				assert.Contains(t, msg.HTML(), "Best regards", "HTML should contain the default salutation text")
-				assert.Contains(
-					t,
-					msg.PlainText(),
-					"Best regards",
-					"PlainText should contain the default salutation text",
+				assert.Equal(t, []string{"<order-123@example.com>"}, msg.Headers()["Message-Id"])
+			},
+		},
+		{
+			name: "generated Message-ID with domain",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().MessageIDDomain("mail.example.com").Header("X-Tag", "promo")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				require.Len(t, msg.Headers()["Message-Id"], 1)
+				assert.Regexp(t,
+					`^<[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}@mail\.example\.com>$`,
+					msg.Headers()["Message-Id"][0],
 				)
+				assert.Equal(t, []string{"promo"}, msg.Headers()["X-Tag"])
+			},
+		},
+		{
+			name: "invalid Message-ID is ignored",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().MessageID("order-123")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.Headers(), "Message-Id")
 			},
 		},
 	}
 	for _, tc := range testCases {
 		tc.run(t)
 	}
+
+	t.Run("generated Message-IDs are unique", func(t *testing.T) {
+		builder := mailgen.New().MessageIDDomain("mail.example.com")
+		first, err := builder.Build()
+		require.NoError(t, err)
+		second, err := builder.Build()
+		require.NoError(t, err)
+		assert.NotEqual(t, first.Headers()["Message-Id"], second.Headers()["Message-Id"])
+	})
 }
 
-func TestBuilder_Line(t *testing.T) {
+func TestBuilder_Priority(t *testing.T) {
 	testCases := []testCase{
 		{
-			name: "add line",
+			name:        "normal priority by default",
+			builderFunc: mailgen.New,
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, mailgen.PriorityNormal, msg.Priority())
+				assert.NotContains(t, msg.Headers(), "X-Priority")
+				assert.NotContains(t, msg.Headers(), "Importance")
+				assert.NotContains(t, msg.Headers(), "Priority")
+			},
+		},
+		{
+			name: "high priority",
 			builderFunc: func() *mailgen.Builder {
-				return mailgen.New().Line("This is a line of text")
+				return mailgen.New().Header("X-Tag", "security").Priority(mailgen.PriorityHigh)
 			},
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				assert.Contains(t, msg.HTML(), "This is a line of text", "HTML should contain the line text")
-				assert.Contains(t, msg.PlainText(), "This is a line of text", "PlainText should contain the line text")
+				assert.Equal(t, mailgen.PriorityHigh, msg.Priority())
+				assert.Equal(t, []string{"1 (Highest)"}, msg.Headers()["X-Priority"])
+				assert.Equal(t, []string{"High"}, msg.Headers()["Importance"])
+				assert.Equal(t, []string{"urgent"}, msg.Headers()["Priority"])
+				assert.Equal(t, []string{"security"}, msg.Headers()["X-Tag"])
 			},
 		},
 		{
-			name: "add multiple lines",
+			name: "low priority",
 			builderFunc: func() *mailgen.Builder {
-				return mailgen.New().Line("First line").Line("Second line")
+				return mailgen.New().Priority(mailgen.PriorityLow)
 			},
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				assert.Contains(t, msg.HTML(), "First line", "HTML should contain the first line text")
-				assert.Contains(t, msg.HTML(), "Second line", "HTML should contain the second line text")
-				assert.Contains(t, msg.PlainText(), "First line", "PlainText should contain the first line text")
-				assert.Contains(t, msg.PlainText(), "Second line", "PlainText should contain the second line text")
+				assert.Equal(t, mailgen.PriorityLow, msg.Priority())
+				assert.Equal(t, []string{"5 (Lowest)"}, msg.Headers()["X-Priority"])
+				assert.Equal(t, []string{"Low"}, msg.Headers()["Importance"])
+				assert.Equal(t, []string{"non-urgent"}, msg.Headers()["Priority"])
 			},
 		},
-	}
-	for _, tc := range testCases {
-		tc.run(t)
-	}
-}
-
-func TestBuilder_Linef(t *testing.T) {
-	testCases := []testCase{
 		{
-			name: "add formatted line",
+			name: "invalid priority is ignored",
 			builderFunc: func() *mailgen.Builder {
-				return mailgen.New().Linef("Hello %s, your order #%d is ready", "John", 12345)
+				return mailgen.New().Priority(mailgen.PriorityHigh).Priority(mailgen.Priority(42))
 			},
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				expectedText := "Hello John, your order #12345 is ready"
-				assert.Contains(t, msg.HTML(), expectedText, "HTML should contain the formatted line text")
-				assert.Contains(t, msg.PlainText(), expectedText, "PlainText should contain the formatted line text")
+				assert.Equal(t, mailgen.PriorityHigh, msg.Priority())
+				assert.Equal(t, "high", msg.Priority().String())
 			},
 		},
 	}
@@ -538,188 +725,2476 @@ func TestBuilder_Linef(t *testing.T) {
 	}
 }
 
-func TestBuilder_Action(t *testing.T) {
+func TestBuilder_Unsubscribe(t *testing.T) {
 	testCases := []testCase{
 		{
-			name: "add action with default color",
+			name: "set https unsubscribe url",
 			builderFunc: func() *mailgen.Builder {
-				return mailgen.New().Action("Click Here", "https://example.com")
+				return mailgen.New().Unsubscribe("https://example.com/unsubscribe")
 			},
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				assert.Contains(t, msg.HTML(), "Click Here", "HTML should contain the action text")
-				assert.Contains(t, msg.HTML(), "https://example.com", "HTML should contain the action URL")
-				assert.Contains(
-					t,
-					msg.HTML(),
-					"background-color:#3869D4",
-					"HTML should contain the default action color",
-				)
-				assert.Contains(
-					t,
-					msg.HTML(),
-					"If you&#39;re having trouble clicking",
-					"HTML should contain the fallback text",
-				)
-				assert.Contains(t, msg.PlainText(), "Click Here", "PlainText should contain the action text")
-				assert.Contains(t, msg.PlainText(), "https://example.com", "PlainText should contain the action URL")
+				assert.Equal(t, []string{"<https://example.com/unsubscribe>"}, msg.Headers()["List-Unsubscribe"])
+				assert.Equal(t, []string{"List-Unsubscribe=One-Click"}, msg.Headers()["List-Unsubscribe-Post"])
+				assert.NotContains(t, msg.HTML(), "Unsubscribe</a>", "HTML should not contain the link by default")
 			},
 		},
 		{
-			name: "add action with custom color",
+			name: "set https and mailto unsubscribe urls",
 			builderFunc: func() *mailgen.Builder {
-				return mailgen.New().Action("Custom Button", "https://custom.com", mailgen.Action{Color: "#FF0000"})
+				return mailgen.New().Unsubscribe("https://example.com/unsubscribe", mailgen.UnsubscribeOption{
+					Mailto: "unsubscribe@example.com",
+				})
 			},
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				assert.Contains(t, msg.HTML(), "Custom Button", "HTML should contain the custom action text")
-				assert.Contains(t, msg.HTML(), "https://custom.com", "HTML should contain the custom action URL")
-				assert.Contains(
-					t,
-					msg.HTML(),
-					"background-color:#FF0000",
-					"HTML should contain the custom action color",
-				)
-				assert.Contains(
-					t,
-					msg.HTML(),
-					"If you&#39;re having trouble clicking",
-					"HTML should contain the fallback text",
-				)
-				assert.Contains(t, msg.PlainText(), "Custom Button", "PlainText should contain the custom action text")
-				assert.Contains(
+				assert.Equal(
 					t,
-					msg.PlainText(),
-					"https://custom.com",
-					"PlainText should contain the custom action URL",
+					[]string{"<https://example.com/unsubscribe>, <mailto:unsubscribe@example.com>"},
+					msg.Headers()["List-Unsubscribe"],
 				)
+				assert.Equal(t, []string{"List-Unsubscribe=One-Click"}, msg.Headers()["List-Unsubscribe-Post"])
 			},
 		},
 		{
-			name: "disable action fallback",
+			name: "set mailto unsubscribe url only",
 			builderFunc: func() *mailgen.Builder {
-				return mailgen.New().Action("No Fallback", "https://nofallback.com", mailgen.Action{NoFallback: true})
+				return mailgen.New().Unsubscribe("mailto:unsubscribe@example.com")
 			},
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				assert.Contains(t, msg.HTML(), "No Fallback", "HTML should contain the action text")
-				assert.Contains(t, msg.HTML(), "https://nofallback.com", "HTML should contain the action URL")
-				assert.NotContains(
-					t,
-					msg.HTML(),
-					"If you&#39;re having trouble clicking",
-					"HTML should not contain the fallback text",
-				)
-				assert.Contains(t, msg.PlainText(), "No Fallback", "PlainText should contain the action text")
-				assert.Contains(t, msg.PlainText(), "https://nofallback.com", "PlainText should contain the action URL")
+				assert.Equal(t, []string{"<mailto:unsubscribe@example.com>"}, msg.Headers()["List-Unsubscribe"])
+				assert.NotContains(t, msg.Headers(), "List-Unsubscribe-Post", "One-click requires a https URL")
 			},
 		},
 		{
-			name: "custom fallback format",
+			name: "show unsubscribe link in footer",
 			builderFunc: func() *mailgen.Builder {
-				return mailgen.New().
-					FallbackFormat("If you cannot click the button, visit this link:").
-					Action("Custom Fallback", "https://customfallback.com")
+				return mailgen.New().Unsubscribe("https://example.com/unsubscribe", mailgen.UnsubscribeOption{
+					ShowLink: true,
+					Text:     "Opt out",
+				})
 			},
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				assert.Contains(t, msg.HTML(), "Custom Fallback", "HTML should contain the action text")
-				assert.Contains(t, msg.HTML(), "https://customfallback.com", "HTML should contain the action URL")
-				assert.Contains(
-					t,
-					msg.HTML(),
+				assert.Contains(t, msg.HTML(), `<a href="https://example.com/unsubscribe"`)
+				assert.Contains(t, msg.HTML(), "Opt out</a>")
+				assert.Contains(t, msg.PlainText(), "Opt out: https://example.com/unsubscribe")
+			},
+		},
+		{
+			name:        "not set unsubscribe",
+			builderFunc: mailgen.New,
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.Headers(), "List-Unsubscribe")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_ViewInBrowser(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "view in browser link",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().ViewInBrowser("https://example.com/emails/123").Line("Hello")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Regexp(
+					t,
+					`(?s)<a href="https://example.com/emails/123"[^>]*>View this email in your browser</a>.*email-masthead`,
+					msg.HTML(),
+					"The link should be rendered above the header",
+				)
+				assert.True(t, strings.HasPrefix(
+					msg.PlainText(),
+					"View this email in your browser: https://example.com/emails/123\n\n[Go-Mailgen]",
+				))
+			},
+		},
+		{
+			name: "view in browser link with custom text",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().ViewInBrowser("https://example.com/emails/123", "Can't see this? View online")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Can&#39;t see this? View online</a>")
+				assert.Contains(t, msg.PlainText(), "Can't see this? View online: https://example.com/emails/123")
+			},
+		},
+		{
+			name: "empty URL removes the view in browser link",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().ViewInBrowser("https://example.com/emails/123").ViewInBrowser("")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), `class="email-browser-link"`)
+				assert.NotContains(t, msg.PlainText(), "View this email in your browser")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_PreferenceCenter(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "show preference center link in footer",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().PreferenceCenter("https://example.com/preferences", "")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `<a href="https://example.com/preferences"`)
+				assert.Contains(t, msg.HTML(), "Manage preferences</a>")
+				assert.Contains(t, msg.PlainText(), "Manage preferences: https://example.com/preferences")
+				assert.NotContains(t, msg.Headers(), "List-Manage", "Header should not be set by default")
+			},
+		},
+		{
+			name: "set custom text and list-manage header",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Theme("plain").
+					PreferenceCenter("https://example.com/preferences", "Email settings", mailgen.PreferenceCenterOption{
+						Header: true,
+					})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Email settings</a>")
+				assert.Contains(t, msg.PlainText(), "Email settings: https://example.com/preferences")
+				assert.Equal(t, []string{"<https://example.com/preferences>"}, msg.Headers()["List-Manage"])
+				assert.NotContains(t, msg.Headers(), "List-Unsubscribe")
+			},
+		},
+		{
+			name: "show preference center with unsubscribe",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					PreferenceCenter("https://example.com/preferences", "", mailgen.PreferenceCenterOption{Header: true}).
+					Unsubscribe("https://example.com/unsubscribe", mailgen.UnsubscribeOption{ShowLink: true})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Manage preferences</a>")
+				assert.Contains(t, msg.HTML(), "Unsubscribe</a>")
+				assert.Equal(t, []string{"<https://example.com/preferences>"}, msg.Headers()["List-Manage"])
+				assert.Equal(t, []string{"<https://example.com/unsubscribe>"}, msg.Headers()["List-Unsubscribe"])
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *mailgen.Builder
+		wantErr error
+	}{
+		{
+			name:    "valid message",
+			builder: mailgen.New().Subject("Welcome").From("no-reply@example.com").To("john@example.com"),
+			wantErr: nil,
+		},
+		{
+			name:    "bcc only recipient",
+			builder: mailgen.New().Subject("Welcome").Bcc("john@example.com"),
+			wantErr: nil,
+		},
+		{
+			name:    "missing subject",
+			builder: mailgen.New().Subject("  ").To("john@example.com"),
+			wantErr: mailgen.ErrNoSubject,
+		},
+		{
+			name:    "missing recipients",
+			builder: mailgen.New().Subject("Welcome"),
+			wantErr: mailgen.ErrNoRecipients,
+		},
+		{
+			name:    "invalid address without strict addresses",
+			builder: mailgen.New().Subject("Welcome").To("john@example.com").Cc("john(at)example.com"),
+			wantErr: mailgen.ErrInvalidAddress,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.builder.Validate()
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	err := mailgen.New().Subject("Welcome").To("john(at)example.com").Validate()
+	require.EqualError(t, err, `mailgen: invalid email address: invalid To address "john(at)example.com"`)
+	_, err = mailgen.New().Build()
+	require.NoError(t, err, "Build should not validate the subject and recipients")
+
+	_, err = mailgen.New().ValidateOnBuild(true).To("john@example.com").Build()
+	require.ErrorIs(t, err, mailgen.ErrNoSubject)
+	_, err = mailgen.New().ValidateOnBuild(true).Subject("Welcome").Build()
+	require.ErrorIs(t, err, mailgen.ErrNoRecipients)
+	_, err = mailgen.New().ValidateOnBuild(true).Subject("Welcome").To("john(at)example.com").Build()
+	require.ErrorIs(t, err, mailgen.ErrInvalidAddress)
+	_, err = mailgen.New().ValidateOnBuild(true).Subject("Welcome").To("john@example.com").Build()
+	require.NoError(t, err)
+}
+
+func TestBuilder_WriteHTMLAndPlainText(t *testing.T) {
+	builder := mailgen.New().
+		Name("John").
+		Line("Your order has shipped.").
+		Action("Track Order", "https://example.com/track")
+	msg, err := builder.Build()
+	require.NoError(t, err)
+
+	var html, text bytes.Buffer
+	require.NoError(t, builder.WriteHTML(&html))
+	require.NoError(t, builder.WritePlainText(&text))
+	assert.Equal(t, msg.HTML(), html.String())
+	assert.Equal(t, msg.PlainText(), text.String())
+
+	builder.AutoPlainText()
+	msg, err = builder.Build()
+	require.NoError(t, err)
+	text.Reset()
+	require.NoError(t, builder.WritePlainText(&text))
+	assert.Equal(t, msg.PlainText(), text.String())
+
+	for _, mode := range []string{mailgen.NewlineCompact, mailgen.NewlineSingle, mailgen.NewlinePreserve} {
+		builder := mailgen.New().
+			PlainTextNewlineMode(mode).
+			LinkParams(map[string]string{"utm_source": "email"}).
+			Line("Your order\n\n\n\nhas shipped.  ").
+			Action("Track Order", "https://example.com/track").
+			Action("Call us", "tel:+15555550100")
+		msg, err := builder.Build()
+		require.NoError(t, err)
+		html.Reset()
+		text.Reset()
+		require.NoError(t, builder.WriteHTML(&html))
+		require.NoError(t, builder.WritePlainText(&text))
+		assert.Equal(t, msg.HTML(), html.String(), "mode %q", mode)
+		assert.Equal(t, msg.PlainText(), text.String(), "mode %q", mode)
+		assert.Contains(t, text.String(), "utm_source=email")
+		assert.Contains(t, text.String(), "Call us (#)")
+	}
+
+	errWrite := errors.New("write failed")
+	require.ErrorIs(t, builder.WriteHTML(failingWriter{err: errWrite}), errWrite)
+	require.ErrorIs(t, builder.WritePlainText(failingWriter{err: errWrite}), errWrite)
+	require.ErrorIs(t, mailgen.New().Line("Hello").WritePlainText(failingWriter{err: errWrite}), errWrite)
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestBuilder_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	_, err := mailgen.New().
+		Logger(logger).
+		Subject("Welcome").
+		To("john@example.com", "jane@example.com").
+		Cc("jane@example.com").
+		Line("Secret body").
+		Build()
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `"level":"DEBUG","msg":"mailgen: built message","subject":"Welcome","recipients":2`)
+	assert.Contains(t, buf.String(), `"elapsed":`)
+	assert.NotContains(t, buf.String(), "Secret body", "Logs should never contain the body")
+
+	buf.Reset()
+	_, err = mailgen.New().
+		Logger(logger).
+		StrictAddresses(true).
+		Subject("Welcome").
+		To("not an address").
+		Build()
+	require.ErrorIs(t, err, mailgen.ErrInvalidAddress)
+	assert.Contains(t, buf.String(), `"level":"ERROR","msg":"mailgen: failed to build message","subject":"Welcome"`)
+	assert.Contains(t, buf.String(), `"error":"mailgen: invalid email address`)
+}
+
+func TestBuilder_WarnOnClipping(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	builder := mailgen.New().Logger(logger).Subject("Digest").Line("Hello")
+
+	msg, err := builder.Build()
+	require.NoError(t, err)
+	assert.Equal(t, len(msg.HTML()), msg.SizeHTML())
+	assert.Equal(t, len(msg.PlainText()), msg.SizeText())
+	assert.Less(t, msg.SizeHTML(), mailgen.DefaultClipThreshold)
+
+	_, err = builder.ClipThreshold(msg.SizeHTML() - 1).Build()
+	require.NoError(t, err)
+	assert.Empty(t, buf.String(), "Nothing should be logged unless WarnOnClipping is enabled")
+
+	_, err = builder.WarnOnClipping(true).ClipThreshold(msg.SizeHTML()).Build()
+	require.NoError(t, err)
+	assert.Empty(t, buf.String(), "Nothing should be logged when the HTML is not larger than the threshold")
+
+	_, err = builder.ClipThreshold(msg.SizeHTML() - 1).Build()
+	require.NoError(t, err, "Clipping should not fail the build")
+	assert.Contains(t, buf.String(), `"level":"WARN","msg":"mailgen: HTML content may be clipped by email clients"`)
+	assert.Contains(t, buf.String(), fmt.Sprintf(`"subject":"Digest","size":%d,"threshold":%d`, msg.SizeHTML(), msg.SizeHTML()-1))
+}
+
+func TestBuilder_Doctype(t *testing.T) {
+	testCases := []testCase{
+		{
+			name:        "default doctype and xmlns attributes",
+			builderFunc: mailgen.New,
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.True(t, strings.HasPrefix(msg.HTML(), mailgen.DoctypeXHTMLTransitional))
+				assert.Contains(t, msg.HTML(), `xmlns="http://www.w3.org/1999/xhtml"`)
+				assert.Contains(t, msg.HTML(), `xmlns:v="urn:schemas-microsoft-com:vml"`)
+				assert.Contains(t, msg.HTML(), `xmlns:o="urn:schemas-microsoft-com:office:office"`)
+			},
+		},
+		{
+			name: "html5 doctype",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Theme("plain").Doctype(mailgen.DoctypeHTML5)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.True(t, strings.HasPrefix(msg.HTML(), "<!DOCTYPE html><html "))
+				assert.Contains(t, msg.HTML(), `xmlns:v="urn:schemas-microsoft-com:vml"`)
+			},
+		},
+		{
+			name: "invalid doctype is ignored",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().UsePremailer(false).Doctype("html")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.True(t, strings.HasPrefix(msg.HTML(), mailgen.DoctypeXHTMLTransitional))
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_AMP(t *testing.T) {
+	testCases := []testCase{
+		{
+			name:        "disabled by default",
+			builderFunc: mailgen.New,
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Empty(t, msg.AMPHTML())
+			},
+		},
+		{
+			name: "amp boilerplate",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().AMP(true).Name("John").Line("Your order has shipped.")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				amp := msg.AMPHTML()
+				assert.True(t, strings.HasPrefix(amp, "<!doctype html><html ⚡4email data-css-strict>"))
+				assert.Contains(t, amp, `<script async src="https://cdn.ampproject.org/v0.js"></script>`)
+				assert.Contains(t, amp, "<style amp4email-boilerplate>body{visibility:hidden}</style>")
+				assert.Contains(t, amp, "<style amp-custom>")
+				assert.Contains(t, amp, "<p>Your order has shipped.</p>")
+				assert.NotContains(t, amp, "!important")
+				assert.NotContains(t, amp, "@import")
+				assert.True(t, strings.HasPrefix(msg.HTML(), mailgen.DoctypeXHTMLTransitional))
+			},
+		},
+		{
+			name: "images are rendered as amp-img",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					AMP(true).
+					Product(mailgen.Product{Name: "Acme", Logo: "https://example.com/logo.png"}).
+					Action("Open", "https://example.com", mailgen.Action{Icon: "https://example.com/icon.png"}).
+					Image(mailgen.Image{Src: "https://example.com/banner.png", Width: "600", Height: "200"}).
+					Image(mailgen.Image{Src: "https://example.com/photo.png"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				amp := msg.AMPHTML()
+				assert.NotContains(t, amp, "<img")
+				assert.Contains(t, amp, `<amp-img src="https://example.com/logo.png" class="email-masthead_logo" alt="Acme"`)
+				assert.Contains(t, amp, `<amp-img src="https://example.com/icon.png" class="button_icon"`)
+				assert.Contains(t, amp, `width="600" height="200" layout="intrinsic"></amp-img>`)
+				assert.Contains(t, amp, `<amp-img src="https://example.com/photo.png" alt="" height="200" layout="fixed-height"`)
+			},
+		},
+		{
+			name: "tables and buttons",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					AMP(true).
+					Table(mailgen.Table{Data: [][]mailgen.Entry{{{Key: "Item", Value: "Widget"}}}}).
+					Action("View order", "https://example.com/orders/1")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				amp := msg.AMPHTML()
+				assert.Contains(t, amp, `<th align="left" style="text-align: left;"><p class="f-fallback">Item</p></th>`)
+				assert.Contains(t, amp, `<a href="https://example.com/orders/1" class="f-fallback button "`)
+				assert.NotContains(t, amp, "<!--")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_BrandColor(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "default brand color",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Action("Go", "https://example.com").Callout(mailgen.Callout{Text: "Note"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "background-color:#3869D4;border-color:#3869D4")
+				assert.Contains(t, msg.HTML(), "border-left:4px solid #3869D4")
+				assert.Contains(t, msg.HTML(), "color:#A8AAAF", "Header should keep its default color")
+			},
+		},
+		{
+			name: "custom brand color",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					BrandColor("#E91E63").
+					Title("Your receipt").
+					Line("Thanks for your purchase.").
+					Action("View order", "https://example.com/orders/1").
+					Actions(
+						mailgen.Action{Text: "Track", Link: "https://example.com/track"},
+						mailgen.Action{Text: "Cancel", Link: "https://example.com/cancel", Color: "#FF6136"},
+					).
+					Callout(mailgen.Callout{Text: "Note"}).
+					SecurityNotice("https://example.com/support")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, 2, strings.Count(msg.HTML(), "background-color:#E91E63;border-color:#E91E63"),
+					"Actions without a color should use the brand color")
+				assert.Contains(t, msg.HTML(), "background-color:#FF6136;border-color:#FF6136")
+				assert.Contains(t, msg.HTML(), "border-left:4px solid #E91E63")
+				assert.Contains(t, msg.HTML(), "border-left:4px solid #FF6136", "Security notice should stay red")
+				assert.Regexp(t, `class="f-fallback email-masthead_name" style="[^"]*color:#E91E63`, msg.HTML())
+				assert.Regexp(t, `<h1 class="title" style="[^"]*color:#E91E63`, msg.HTML())
+				assert.Regexp(t, `<a href="https://example.com/support" target="_blank" style="color:#E91E63`, msg.HTML())
+			},
+		},
+		{
+			name: "invalid brand color is ignored",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().BrandColor("#E91E63").BrandColor("red; background: url(x)").Action("Go", "https://example.com")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "background-color:#E91E63;border-color:#E91E63")
+				assert.NotContains(t, msg.HTML(), "url(x)")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_FontAndColors(t *testing.T) {
+	testCases := []testCase{
+		{
+			name:        "default font and colors",
+			builderFunc: mailgen.New,
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "font-family:&#34;Nunito Sans&#34;, Helvetica, Arial, sans-serif")
+				assert.Regexp(t, `class="email-wrapper" [^>]*background-color:#F2F4F6`, msg.HTML())
+				assert.Regexp(t, `class="email-body_inner" [^>]*background-color:#FFFFFF`, msg.HTML())
+			},
+		},
+		{
+			name: "custom font and colors",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Font(`"Open Sans", Arial, sans-serif`).Colors("#FFFDF8", "#F5F1E8")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "font-family:&#34;Open Sans&#34;, Arial, sans-serif")
+				assert.NotContains(t, msg.HTML(), "Nunito Sans")
+				assert.Regexp(t, `<body [^>]*background-color:#F5F1E8`, msg.HTML())
+				assert.Regexp(t, `class="email-wrapper" [^>]*background-color:#F5F1E8`, msg.HTML())
+				assert.Regexp(t, `class="email-body_inner" [^>]*background-color:#FFFDF8`, msg.HTML())
+			},
+		},
+		{
+			name: "invalid and empty values keep the current values",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Font("Georgia, serif").
+					Font("Arial; } body { display: none").
+					Colors("#FFFDF8", "#F5F1E8").
+					Colors("", "beige")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "font-family:Georgia, serif")
+				assert.NotContains(t, msg.HTML(), "display: none")
+				assert.Regexp(t, `class="email-wrapper" [^>]*background-color:#F5F1E8`, msg.HTML())
+				assert.Regexp(t, `class="email-body_inner" [^>]*background-color:#FFFDF8`, msg.HTML())
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_PlainTextHeader(t *testing.T) {
+	product := mailgen.Product{Name: "Acme", Link: "https://example.com", Logo: "https://example.com/logo.png"}
+	testCases := []testCase{
+		{
+			name: "header shows the product name and link by default",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Product(product).Line("Hello")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.True(t, strings.HasPrefix(msg.PlainText(), "[Acme] (https://example.com)\n\n"))
+				assert.NotContains(t, msg.PlainText(), "logo.png", "PlainText should not contain the logo")
+			},
+		},
+		{
+			name: "header without a product link",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Product(mailgen.Product{Name: "Acme"}).Line("Hello")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.True(t, strings.HasPrefix(msg.PlainText(), "[Acme]\n\n"))
+			},
+		},
+		{
+			name: "header disabled",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Product(product).PlainTextHeader(false).Line("Hello")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.True(t, strings.HasPrefix(msg.PlainText(), "***\nHi,\n***\n\nHello"))
+				assert.NotContains(t, msg.PlainText(), "[Acme]")
+				assert.Contains(t, msg.HTML(), "https://example.com/logo.png", "HTML header should not be affected")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_DarkMode(t *testing.T) {
+	testCases := []testCase{
+		{
+			name:        "dark mode enabled by default",
+			builderFunc: mailgen.New,
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `<meta name="color-scheme" content="light dark"/>`)
+				assert.Contains(t, msg.HTML(), `<meta name="supported-color-schemes" content="light dark"/>`)
+				assert.Contains(t, msg.HTML(), `style="color-scheme: light dark; supported-color-schemes: light dark;"`)
+				assert.Contains(t, msg.HTML(), "@media (prefers-color-scheme: dark)")
+				assert.Contains(t, msg.HTML(), "color: #8AB4F8 !important", "Links should use a lighter accent")
+			},
+		},
+		{
+			name: "brand color is kept in dark mode",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().BrandColor("#E91E63")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "@media (prefers-color-scheme: dark)")
+				assert.NotContains(t, msg.HTML(), "#8AB4F8")
+			},
+		},
+		{
+			name: "dark mode disabled",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().DarkMode(false)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), "color-scheme")
+				assert.NotContains(t, msg.HTML(), "prefers-color-scheme")
+				assert.NotContains(t, msg.HTML(), "#8AB4F8")
+				assert.Contains(t, msg.HTML(), "@media only screen and (max-width: 600px)")
+			},
+		},
+		{
+			name: "dark mode disabled in plain theme",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Theme("plain").DarkMode(false)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), "color-scheme")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_Preheader(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "set preheader",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Preheader("This is a preheader text")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "This is a preheader text", "HTML should contain the preheader text")
+				assert.Contains(
+					t,
+					msg.PlainText(),
+					"This is a preheader text",
+					"PlainText should contain the preheader text",
+				)
+			},
+		},
+		{
+			name: "preheader is hidden and padded",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().UsePremailer(false).Preheader("Your order has shipped")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(
+					t,
+					msg.HTML(),
+					`<span class="preheader">Your order has shipped&#847;&zwnj;&nbsp;&#847;&zwnj;&nbsp;`,
+					"HTML should pad the hidden preheader",
+				)
+				assert.Equal(
+					t,
+					128,
+					strings.Count(msg.HTML(), "&#847;&zwnj;&nbsp;"),
+					"Preheader should be padded to 150 characters",
+				)
+				assert.NotContains(t, msg.PlainText(), "&zwnj;", "PlainText should not contain the padding")
+			},
+		},
+		{
+			name: "preheader is padded after CSS inlining",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Preheader("Your order has shipped")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Your order has shipped\u034f\u200c\u00a0\u034f\u200c\u00a0")
+				assert.Contains(t, msg.HTML(), "display:none", "HTML should hide the preheader")
+			},
+		},
+		{
+			name: "long preheader is not padded",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().UsePremailer(false).Preheader(strings.Repeat("a", 150))
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), "&zwnj;")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_GreetingFormat(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "custom order and punctuation",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Greeting("Hello").Name("John").GreetingFormat("[GREETING], [NAME]!")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), ">Hello, John!</h1>")
+				assert.Contains(t, msg.PlainText(), "Hello, John!\n")
+				assert.NotContains(t, msg.PlainText(), "Hello, John!,")
+			},
+		},
+		{
+			name: "name placeholder is removed without a name",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Greeting("Dear").GreetingFormat("[GREETING] [NAME]:")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), ">Dear:</h1>")
+				assert.Contains(t, msg.PlainText(), "Dear:\n")
+			},
+		},
+		{
+			name: "format overrides the rtl order",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().TextDirection("rtl").Greeting("مرحبا").Name("أحمد").GreetingFormat("[GREETING] [NAME]،")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "مرحبا أحمد،\n")
+			},
+		},
+		{
+			name: "name is escaped in html",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Name("<b>John</b>").GreetingFormat("[GREETING] [NAME]!")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), ">Hi &lt;b&gt;John&lt;/b&gt;!</h1>")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_Greeting(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "set greeting",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Greeting("Hi there")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Hi there", "HTML should contain the greeting text")
+				assert.Contains(t, msg.PlainText(), "Hi there", "PlainText should contain the greeting text")
+			},
+		},
+		{
+			name:        "not set greeting should use default",
+			builderFunc: mailgen.New,
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Hi", "HTML should contain the default greeting text")
+				assert.Contains(t, msg.PlainText(), "Hi", "PlainText should contain the default greeting text")
+			},
+		},
+		{
+			name: "no greeting",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Greeting("Hello").Name("John Doe").NoGreeting().Line("Your export is ready.")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), "<h1", "HTML should not contain the greeting")
+				assert.NotContains(t, msg.PlainText(), "Hello", "PlainText should not contain the greeting")
+				assert.Contains(
+					t,
+					msg.PlainText(),
+					"(https://github.com/akfaiz/go-mailgen)\n\nYour export is ready.\n\nBest regards",
+					"PlainText should start the body with the first component",
+				)
+			},
+		},
+		{
+			name: "set greeting with name",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Greeting("Hello").Name("John Doe")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Hello John Doe", "HTML should contain the greeting with name")
+				assert.Contains(t, msg.PlainText(), "Hello John Doe", "PlainText should contain the greeting with name")
+			},
+		},
+		{
+			name: "set name without greeting",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Name("Jane Doe")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Hi Jane Doe", "HTML should contain the default greeting with name")
+				assert.Contains(
+					t,
+					msg.PlainText(),
+					"Hi Jane Doe",
+					"PlainText should contain the default greeting with name",
+				)
+			},
+		},
+		{
+			name: "set empty greeting",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Greeting("")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Hi", "HTML should contain the default greeting text")
+				assert.Contains(t, msg.PlainText(), "Hi", "PlainText should contain the default greeting text")
+			},
+		},
+		{
+			name: "set whitespace-only name",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Name("   ")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "<h1", "HTML should contain the greeting")
+				assert.Contains(t, msg.HTML(), ">Hi,</h1>", "HTML greeting should not contain trailing spaces")
+				assert.Contains(
+					t,
+					msg.PlainText(),
+					"***\nHi,\n***",
+					"PlainText greeting should not contain trailing spaces",
+				)
+			},
+		},
+		{
+			name: "set name with surrounding whitespace",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Name("  Jane Doe  ")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), ">Hi Jane Doe,</h1>")
+				assert.Contains(t, msg.PlainText(), "\nHi Jane Doe,\n")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_Salutation(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "set salutation",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Salutation("Kind regards")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Kind regards", "HTML should contain the salutation text")
+				assert.Contains(t, msg.PlainText(), "Kind regards", "PlainText should contain the salutation text")
+			},
+		},
+		{
+			name:        "not set salutation should use default",
+			builderFunc: mailgen.New,
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Best regards", "HTML should contain the default salutation text")
+				assert.Contains(
+					t,
+					msg.PlainText(),
+					"Best regards",
+					"PlainText should contain the default salutation text",
+				)
+			},
+		},
+		{
+			name: "no salutation",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Salutation("Kind regards").NoSalutation().Line("Disk usage is above 90%.")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), "Kind regards", "HTML should not contain the salutation")
+				assert.NotContains(t, msg.PlainText(), "Kind regards", "PlainText should not contain the salutation")
+				assert.Contains(
+					t,
+					msg.PlainText(),
+					"Disk usage is above 90%.\n\n© ",
+					"PlainText should continue with the footer after the last component",
+				)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_Signature(t *testing.T) {
+	signature := mailgen.Signature{
+		Name:     "Jane Doe",
+		Title:    "Account Manager, Acme",
+		ImageURL: "https://example.com/jane.png",
+	}
+	testCases := []testCase{
+		{
+			name: "signature below the salutation",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Salutation("Kind regards").Signature(signature).Line("Hello")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				html := msg.HTML()
+				assert.Contains(t, html, `<img src="https://example.com/jane.png" alt="Jane Doe" width="48" height="48"`)
+				assert.Regexp(
+					t,
+					`(?s)Kind regards,</p><table class="signature".*>Jane Doe</p><p class="sub"[^>]*>Account Manager, Acme</p>`,
+					html,
+				)
+				assert.NotContains(t, html, "Kind regards,<br>", "The product name should be replaced by the signature")
+				assert.Contains(t, msg.PlainText(), "Hello\n\nKind regards,\nJane Doe\nAccount Manager, Acme\n\n©")
+			},
+		},
+		{
+			name: "signature without salutation, title and image",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().NoSalutation().Signature(mailgen.Signature{Name: "Jane Doe"}).Line("Hello")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), "Best regards")
+				assert.NotContains(t, msg.HTML(), "signature_image")
+				assert.Contains(t, msg.HTML(), ">Jane Doe</p>")
+				assert.Contains(t, msg.PlainText(), "Hello\n\nJane Doe\n\n©")
+			},
+		},
+		{
+			name: "signature without name is ignored",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Signature(mailgen.Signature{Title: "Account Manager"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), `class="signature"`)
+				assert.Contains(t, msg.PlainText(), "Best regards,\nGo-Mailgen")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_Bare(t *testing.T) {
+	msg, err := mailgen.New().
+		Bare().
+		Name("John").
+		Line("Export job 42 completed").
+		Line("Rows exported: 1000").
+		Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, "Export job 42 completed\n\nRows exported: 1000", msg.PlainText())
+
+	assert.Contains(t, msg.HTML(), "Export job 42 completed")
+	assert.NotContains(t, msg.HTML(), "Hi John", "HTML should not contain the greeting")
+	assert.NotContains(t, msg.HTML(), "Best regards", "HTML should not contain the salutation")
+	assert.NotContains(t, msg.HTML(), "All rights reserved", "HTML should not contain the copyright")
+}
+
+func TestBuilder_Line(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "add line",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Line("This is a line of text")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "This is a line of text", "HTML should contain the line text")
+				assert.Contains(t, msg.PlainText(), "This is a line of text", "PlainText should contain the line text")
+			},
+		},
+		{
+			name: "add multiple lines",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Line("First line").Line("Second line")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "First line", "HTML should contain the first line text")
+				assert.Contains(t, msg.HTML(), "Second line", "HTML should contain the second line text")
+				assert.Contains(t, msg.PlainText(), "First line", "PlainText should contain the first line text")
+				assert.Contains(t, msg.PlainText(), "Second line", "PlainText should contain the second line text")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_WrapText(t *testing.T) {
+	longLine := "The quick brown fox jumps over the lazy dog and keeps running through the field until sunset."
+	testCases := []testCase{
+		{
+			name: "wrap long lines at word boundary",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().WrapText(40).Line(longLine)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(
+					t,
+					msg.PlainText(),
+					"The quick brown fox jumps over the lazy\ndog and keeps running through the field\nuntil sunset.",
+				)
+				assert.Contains(t, msg.HTML(), longLine, "HTML should not be wrapped")
+			},
+		},
+		{
+			name: "preserve explicit newlines and urls",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					WrapText(20).
+					Line("Short line\nVisit https://example.com/a/very/long/path/that/must/not/break today")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(
+					t,
+					msg.PlainText(),
+					"Short line\nVisit\nhttps://example.com/a/very/long/path/that/must/not/break\ntoday",
+				)
+			},
+		},
+		{
+			name: "indent wrapped list items",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().WrapText(20).List([]string{"Complete your profile to get started"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "- Complete your\n  profile to get\n  started")
+			},
+		},
+		{
+			name: "tables are not wrapped",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().WrapText(20).Table(mailgen.Table{
+					Data: [][]mailgen.Entry{
+						{{Key: "Description", Value: "An open-source programming language"}},
+					},
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "An open-source programming language")
+			},
+		},
+		{
+			name: "wrapping is disabled by default",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Line(longLine)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), longLine)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_Markdown(t *testing.T) {
+	msg, err := mailgen.New().
+		Markdown("Your order **#1234** has shipped. [Track it](https://example.com/track) anytime.").
+		Build()
+	require.NoError(t, err)
+
+	assert.Contains(t, msg.HTML(), "<strong>#1234</strong>")
+	assert.Contains(t, msg.HTML(), `<a href="https://example.com/track" target="_blank"`)
+	assert.Contains(t, msg.PlainText(), "Your order #1234 has shipped. Track it (https://example.com/track) anytime.")
+
+	msg, err = mailgen.New().Markdown("hello \x007\x00 world [link](https://example.com) \x000\x00").Build()
+	require.NoError(t, err, "Text resembling the link placeholders should not break the rendering")
+	assert.Contains(t, msg.HTML(), `hello 7 world <a href="https://example.com" target="_blank"`)
+	assert.Contains(t, msg.HTML(), "</a> 0")
+}
+
+func TestBuilder_Linef(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "add formatted line",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Linef("Hello %s, your order #%d is ready", "John", 12345)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				expectedText := "Hello John, your order #12345 is ready"
+				assert.Contains(t, msg.HTML(), expectedText, "HTML should contain the formatted line text")
+				assert.Contains(t, msg.PlainText(), expectedText, "PlainText should contain the formatted line text")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_Action(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "add action with default color",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Action("Click Here", "https://example.com")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Click Here", "HTML should contain the action text")
+				assert.Contains(t, msg.HTML(), "https://example.com", "HTML should contain the action URL")
+				assert.Contains(
+					t,
+					msg.HTML(),
+					"background-color:#3869D4",
+					"HTML should contain the default action color",
+				)
+				assert.Contains(
+					t,
+					msg.HTML(),
+					"If you&#39;re having trouble clicking",
+					"HTML should contain the fallback text",
+				)
+				assert.Contains(t, msg.PlainText(), "Click Here", "PlainText should contain the action text")
+				assert.Contains(t, msg.PlainText(), "https://example.com", "PlainText should contain the action URL")
+			},
+		},
+		{
+			name: "add action with emoji icon",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Action("Get Started", "https://example.com", mailgen.Action{Icon: "🚀"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `target="_blank">🚀 Get Started</a>`)
+				assert.Contains(t, msg.PlainText(), "🚀 Get Started (https://example.com)")
+				assert.Contains(t, msg.HTML(), "clicking the &#34;Get Started&#34; button", "Fallback should not contain the icon")
+			},
+		},
+		{
+			name: "add action with image icon",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Actions(mailgen.Action{
+					Text: "Download",
+					Link: "https://example.com/app",
+					Icon: "https://example.com/icon.png",
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Regexp(
+					t,
+					`target="_blank"><img src="https://example.com/icon.png" class="button_icon" width="16" height="16" alt="" style="width:16px;height:16px;[^"]*"/>Download</a>`,
+					msg.HTML(),
+				)
+				assert.Contains(t, msg.PlainText(), "Download (https://example.com/app)")
+				assert.NotContains(t, msg.PlainText(), "icon.png")
+			},
+		},
+		{
+			name: "add full-width action",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Action("Pay now", "https://example.com/pay", mailgen.Action{FullWidth: true}).
+					Action("Details", "https://example.com/details", mailgen.Action{Style: "secondary", FullWidth: true}).
+					Action("Later", "https://example.com/later")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Regexp(t, `class="f-fallback button  button--full" style="[^"]*display:block;width:100%;text-align:center`,
+					msg.HTML())
+				assert.Contains(t, msg.HTML(), `class="f-fallback button button--secondary button--full"`)
+				assert.Regexp(t, `class="f-fallback button " style="[^"]*display:inline-block`, msg.HTML())
+				assert.Contains(t, msg.PlainText(), "Pay now (https://example.com/pay)")
+			},
+		},
+		{
+			name: "add action with custom color",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Action("Custom Button", "https://custom.com", mailgen.Action{Color: "#FF0000"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Custom Button", "HTML should contain the custom action text")
+				assert.Contains(t, msg.HTML(), "https://custom.com", "HTML should contain the custom action URL")
+				assert.Contains(
+					t,
+					msg.HTML(),
+					"background-color:#FF0000",
+					"HTML should contain the custom action color",
+				)
+				assert.Contains(
+					t,
+					msg.HTML(),
+					"If you&#39;re having trouble clicking",
+					"HTML should contain the fallback text",
+				)
+				assert.Contains(t, msg.PlainText(), "Custom Button", "PlainText should contain the custom action text")
+				assert.Contains(
+					t,
+					msg.PlainText(),
+					"https://custom.com",
+					"PlainText should contain the custom action URL",
+				)
+			},
+		},
+		{
+			name: "disable action fallback",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Action("No Fallback", "https://nofallback.com", mailgen.Action{NoFallback: true})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "No Fallback", "HTML should contain the action text")
+				assert.Contains(t, msg.HTML(), "https://nofallback.com", "HTML should contain the action URL")
+				assert.NotContains(
+					t,
+					msg.HTML(),
+					"If you&#39;re having trouble clicking",
+					"HTML should not contain the fallback text",
+				)
+				assert.Contains(t, msg.PlainText(), "No Fallback", "PlainText should contain the action text")
+				assert.Contains(t, msg.PlainText(), "https://nofallback.com", "PlainText should contain the action URL")
+			},
+		},
+		{
+			name: "add action with plain text override",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Action("Get Started", "https://example.com/start", mailgen.Action{
+					PlainTextOverride: "Tap here to get started: https://example.com/start",
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "Tap here to get started: https://example.com/start")
+				assert.NotContains(t, msg.PlainText(), "Get Started (https://example.com/start)")
+				assert.Contains(t, msg.HTML(), "Get Started", "HTML should still render the button text")
+			},
+		},
+		{
+			name: "add primary and secondary actions",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Action("Accept", "https://example.com/accept").
+					Action("Maybe later", "https://example.com/later", mailgen.Action{
+						Style: "secondary",
+						Color: "#22BC66",
+					})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `<a href="https://example.com/accept" class="f-fallback button "`)
+				assert.Contains(
+					t,
+					msg.HTML(),
+					`<a href="https://example.com/later" class="f-fallback button button--secondary"`,
+					"HTML should render the secondary action with the outline class",
+				)
+				assert.Contains(t, msg.HTML(), "background-color:#FFFFFF;border:2px solid;")
+				assert.Contains(t, msg.HTML(), "border-color:#22BC66;color:#22BC66", "Color should control the outline")
+			},
+		},
+		{
+			name: "custom fallback format",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					FallbackFormat("If you cannot click the button, visit this link:").
+					Action("Custom Fallback", "https://customfallback.com")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Custom Fallback", "HTML should contain the action text")
+				assert.Contains(t, msg.HTML(), "https://customfallback.com", "HTML should contain the action URL")
+				assert.Contains(
+					t,
+					msg.HTML(),
 					"If you cannot click the button, visit this link:",
 					"HTML should contain the custom fallback text",
 				)
-				assert.Contains(t, msg.PlainText(), "Custom Fallback", "PlainText should contain the action text")
+				assert.Contains(t, msg.PlainText(), "Custom Fallback", "PlainText should contain the action text")
+				assert.Contains(
+					t,
+					msg.PlainText(),
+					"https://customfallback.com",
+					"PlainText should contain the action URL",
+				)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_Actions(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "add action group",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Actions(
+					mailgen.Action{Text: "Yes", Link: "https://example.com/yes"},
+					mailgen.Action{Text: "No", Link: "https://example.com/no", Style: "secondary"},
+				)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, 1, strings.Count(msg.HTML(), `class="body-action"`), "Actions should share one row")
+				assert.Equal(t, 2, strings.Count(msg.HTML(), `class="button-group_item"`))
+				assert.Contains(t, msg.HTML(), `<a href="https://example.com/yes" class="f-fallback button "`)
+				assert.Contains(
+					t,
+					msg.HTML(),
+					`<a href="https://example.com/no" class="f-fallback button button--secondary"`,
+				)
+				assert.Contains(t, msg.HTML(), `If you&#39;re having trouble clicking the &#34;Yes&#34; button`)
+				assert.Contains(t, msg.HTML(), `If you&#39;re having trouble clicking the &#34;No&#34; button`)
+				assert.Contains(t, msg.PlainText(), "Yes (https://example.com/yes)\nNo (https://example.com/no)")
+			},
+		},
+		{
+			name: "add action group without fallback",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Actions(
+					mailgen.Action{Text: "Yes", Link: "https://example.com/yes", NoFallback: true},
+					mailgen.Action{Text: "No", Link: "https://example.com/no"},
+				)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), `clicking the &#34;Yes&#34; button`)
+				assert.Contains(t, msg.HTML(), `clicking the &#34;No&#34; button`)
+			},
+		},
+		{
+			name: "add empty action group",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Actions()
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), `class="body-action"`)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_FallbackPlacement(t *testing.T) {
+	newBuilder := func() *mailgen.Builder {
+		return mailgen.New().
+			UsePremailer(false).
+			Action("First", "https://example.com/first").
+			Line("Second section").
+			Action("Second", "https://example.com/second")
+	}
+	firstFallback := `clicking the &#34;First&#34; button`
+	secondFallback := `clicking the &#34;Second&#34; button`
+
+	testCases := []testCase{
+		{
+			name:        "fallbacks at the end by default",
+			builderFunc: newBuilder,
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				html := msg.HTML()
+				assert.Less(t, strings.Index(html, "Second section"), strings.Index(html, firstFallback))
+				assert.Less(t, strings.Index(html, "Best regards"), strings.Index(html, firstFallback))
+				assert.Less(t, strings.Index(html, firstFallback), strings.Index(html, secondFallback))
+			},
+		},
+		{
+			name: "fallbacks at the end",
+			builderFunc: func() *mailgen.Builder {
+				return newBuilder().FallbackPlacement(mailgen.FallbackEnd)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				html := msg.HTML()
+				assert.Less(t, strings.Index(html, "Best regards"), strings.Index(html, firstFallback))
+			},
+		},
+		{
+			name: "fallbacks after each action",
+			builderFunc: func() *mailgen.Builder {
+				return newBuilder().FallbackPlacement(mailgen.FallbackAfterAction)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				html := msg.HTML()
+				assert.Equal(t, 1, strings.Count(html, firstFallback), "Fallback should be rendered once")
+				assert.Less(t, strings.Index(html, firstFallback), strings.Index(html, "Second section"))
+				assert.Less(t, strings.Index(html, "Second section"), strings.Index(html, secondFallback))
+				assert.Less(t, strings.Index(html, secondFallback), strings.Index(html, "Best regards"))
+			},
+		},
+		{
+			name: "inline alias renders fallbacks after each action",
+			builderFunc: func() *mailgen.Builder {
+				return newBuilder().FallbackPlacement(mailgen.FallbackInline)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				html := msg.HTML()
+				assert.Less(t, strings.Index(html, firstFallback), strings.Index(html, "Second section"))
+				assert.Less(t, strings.Index(html, secondFallback), strings.Index(html, "Best regards"))
+			},
+		},
+		{
+			name: "footer alias renders fallbacks at the end",
+			builderFunc: func() *mailgen.Builder {
+				return newBuilder().FallbackPlacement("inline").FallbackPlacement("footer")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				html := msg.HTML()
+				assert.Less(t, strings.Index(html, "Best regards"), strings.Index(html, firstFallback))
+			},
+		},
+		{
+			name: "invalid placement should not change default",
+			builderFunc: func() *mailgen.Builder {
+				return newBuilder().FallbackPlacement("middle")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				html := msg.HTML()
+				assert.Less(t, strings.Index(html, "Best regards"), strings.Index(html, firstFallback))
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_PostmarkCompatibilityMarkers(t *testing.T) {
+	for _, theme := range []string{"default", "plain"} {
+		baseMsg, err := mailgen.New().
+			Theme(theme).
+			Action("Click Here", "https://example.com").
+			Build()
+		require.NoError(t, err)
+
+		assert.Contains(
+			t,
+			baseMsg.HTML(),
+			`style="color-scheme: light dark; supported-color-schemes: light dark;"`,
+			"HTML root should include color scheme metadata style",
+		)
+
+		greenMsg, err := mailgen.New().
+			Theme(theme).
+			Action("Green", "https://example.com", mailgen.Action{Color: "#22BC66"}).
+			Build()
+		require.NoError(t, err)
+		assert.Contains(
+			t,
+			greenMsg.HTML(),
+			`class="f-fallback button button--green"`,
+			"HTML should include Postmark-compatible green button class in action link",
+		)
+
+		redMsg, err := mailgen.New().
+			Theme(theme).
+			Action("Red", "https://example.com", mailgen.Action{Color: "#FF6136"}).
+			Build()
+		require.NoError(t, err)
+		assert.Contains(
+			t,
+			redMsg.HTML(),
+			`class="f-fallback button button--red"`,
+			"HTML should include Postmark-compatible red button class in action link",
+		)
+	}
+}
+
+func TestBuilder_UsePremailer(t *testing.T) {
+	t.Run("enabled by default", func(t *testing.T) {
+		msg, err := mailgen.New().
+			Line("Hello").
+			Build()
+		require.NoError(t, err)
+
+		assert.Contains(t, msg.HTML(), `style="`, "HTML should include inline styles when premailer is enabled")
+	})
+
+	t.Run("disabled should keep template classes", func(t *testing.T) {
+		msg, err := mailgen.New().
+			UsePremailer(false).
+			Line("Hello").
+			Build()
+		require.NoError(t, err)
+
+		assert.Contains(
+			t,
+			msg.HTML(),
+			`class="email-wrapper"`,
+			"HTML should keep template classes when premailer is disabled",
+		)
+		assert.NotContains(
+			t,
+			msg.HTML(),
+			`style="height:100%;margin:0;`,
+			"HTML body should not be inlined when premailer is disabled",
+		)
+	})
+
+	t.Run("InlineCSS toggles CSS inlining", func(t *testing.T) {
+		msg, err := mailgen.New().InlineCSS(false).Line("Hello").Action("Go", "https://example.com").Build()
+		require.NoError(t, err)
+		assert.Contains(t, msg.HTML(), "<style", "HTML should keep the style block")
+		assert.NotContains(t, msg.HTML(), `style="height:100%;margin:0;`, "HTML should not be inlined")
+
+		msg, err = mailgen.New().InlineCSS(false).InlineCSS(true).Line("Hello").Build()
+		require.NoError(t, err)
+		assert.Contains(t, msg.HTML(), `style="height:100%;margin:0;`, "HTML should be inlined")
+	})
+}
+
+func TestBuilder_PremailerOptions(t *testing.T) {
+	builders := map[string]func() *mailgen.Builder{
+		"lines only": func() *mailgen.Builder {
+			return mailgen.New().Preheader("Preview").Line("Hello")
+		},
+		"with action": func() *mailgen.Builder {
+			return mailgen.New().Preheader("Preview").Line("Hello").Action("Go", "https://example.com")
+		},
+	}
+	for name, builderFunc := range builders {
+		t.Run(name, func(t *testing.T) {
+			msg, err := builderFunc().Build()
+			require.NoError(t, err)
+			assert.Contains(t, msg.HTML(), `class="email-wrapper"`, "Default options should keep classes")
+			assert.NotContains(t, msg.HTML(), "display:none !important", "Default options should drop !important")
+
+			msg, err = builderFunc().
+				PremailerOptions(premailer.NewOptions(
+					premailer.WithRemoveClasses(true),
+					premailer.WithKeepBangImportant(true),
+				)).
+				Build()
+			require.NoError(t, err)
+			assert.NotContains(t, msg.HTML(), `class="email-wrapper"`, "Classes should be removed")
+			assert.Contains(t, msg.HTML(), "display:none !important", "!important should be kept")
+
+			msg, err = builderFunc().
+				PremailerOptions(premailer.NewOptions(premailer.WithRemoveClasses(true))).
+				PremailerOptions(nil).
+				Build()
+			require.NoError(t, err)
+			assert.Contains(t, msg.HTML(), `class="email-wrapper"`, "Nil options should restore the defaults")
+		})
+	}
+}
+
+func TestBuilder_ActionLinks(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "disallowed links are rendered as #",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Action("Open", "javascript:alert(1)").
+					Actions(
+						mailgen.Action{Text: "Visit", Link: "https://example.com"},
+						mailgen.Action{Text: "Run", Link: "data:text/html,x"},
+					).
+					Action("Relative", "/account")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), "javascript:")
+				assert.NotContains(t, msg.PlainText(), "javascript:")
+				assert.NotContains(t, msg.HTML(), "data:text/html")
+				assert.NotContains(t, msg.HTML(), "/account")
+				assert.Contains(t, msg.HTML(), `href="https://example.com"`)
+				assert.Contains(t, msg.PlainText(), "Open (#)")
+			},
+		},
+		{
+			name: "custom allowed schemes",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					AllowedLinkSchemes("HTTPS", "tel:").
+					Action("Call us", "tel:+15555550100").
+					Action("Visit", "http://example.com")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "Call us (tel:+15555550100)")
+				assert.Contains(t, msg.PlainText(), "Visit (#)", "http should not be allowed by the custom schemes")
+			},
+		},
+		{
+			name: "strict links reject disallowed links",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().StrictLinks(true).Action("Open", "javascript:alert(1)")
+			},
+			expectError: true,
+		},
+		{
+			name: "strict links accept allowed links",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					StrictLinks(true).
+					Action("Visit", "https://example.com").
+					MailtoAction("Email us", "support@example.com")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `href="https://example.com"`)
+				assert.Contains(t, msg.HTML(), `href="mailto:support@example.com"`)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+
+	_, err := mailgen.New().StrictLinks(true).Action("Open", "javascript:alert(1)").Build()
+	require.ErrorIs(t, err, mailgen.ErrInvalidLink)
+	assert.Contains(t, err.Error(), `"javascript:alert(1)" of action "Open"`)
+
+	t.Run("sanitizing keeps the original links", func(t *testing.T) {
+		originalDefault := mailgen.New()
+		defer mailgen.SetDefault(originalDefault)
+		mailgen.SetDefault(mailgen.New().Action("Call us", "tel:+15555550100"))
+
+		msg, err := mailgen.New().Build()
+		require.NoError(t, err)
+		assert.Contains(t, msg.PlainText(), "Call us (#)")
+
+		builder := mailgen.New()
+		_, err = builder.Build()
+		require.NoError(t, err)
+		msg, err = builder.AllowedLinkSchemes("tel").Build()
+		require.NoError(t, err)
+		assert.Contains(t, msg.PlainText(), "Call us (tel:+15555550100)",
+			"Allowing the scheme after a build should restore the link")
+
+		msg, err = mailgen.New().AllowedLinkSchemes("tel").Build()
+		require.NoError(t, err)
+		assert.Contains(t, msg.PlainText(), "Call us (tel:+15555550100)", "The default builder should keep the original link")
+	})
+}
+
+func TestBuilder_LinkParams(t *testing.T) {
+	utm := map[string]string{"utm_source": "newsletter", "utm_campaign": "spring sale"}
+	testCases := []testCase{
+		{
+			name: "params are added to action links",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					LinkParams(utm).
+					Action("Shop", "https://example.com/shop").
+					Actions(mailgen.Action{Text: "Blog", Link: "http://example.com/blog?page=2#top"}).
+					Action("Email", "mailto:sales@example.com")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `href="https://example.com/shop?utm_campaign=spring+sale&amp;utm_source=newsletter"`)
+				assert.Contains(t, msg.HTML(), `href="http://example.com/blog?page=2&amp;utm_campaign=spring+sale&amp;utm_source=newsletter#top"`)
+				assert.Contains(t, msg.HTML(), `href="mailto:sales@example.com"`)
+				assert.Contains(t, msg.PlainText(), "https://example.com/shop?utm_campaign=spring+sale&utm_source=newsletter")
+			},
+		},
+		{
+			name: "existing params are kept",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					LinkParams(utm).
+					Action("Shop", "https://example.com/shop?utm_source=partner&ref=a%20b")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "https://example.com/shop?utm_source=partner&ref=a%20b&utm_campaign=spring+sale")
+			},
+		},
+		{
+			name: "existing params are overridden",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					LinkParams(utm).
+					OverrideLinkParams(true).
+					Action("Shop", "https://example.com/shop?utm_source=partner&ref=a%20b")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(),
+					"https://example.com/shop?ref=a%20b&utm_campaign=spring+sale&utm_source=newsletter")
+			},
+		},
+		{
+			name: "params are removed",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					LinkParams(utm).
+					LinkParams(nil).
+					Action("Shop", "https://example.com/shop")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `href="https://example.com/shop"`)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+
+	t.Run("building twice adds the params once", func(t *testing.T) {
+		builder := mailgen.New().LinkParams(utm).Action("Shop", "https://example.com/shop")
+		first, err := builder.Build()
+		require.NoError(t, err)
+		second, err := builder.Build()
+		require.NoError(t, err)
+		assert.Equal(t, first.HTML(), second.HTML())
+	})
+
+	t.Run("changing the params after a build replaces them", func(t *testing.T) {
+		builder := mailgen.New().LinkParams(utm).Action("Shop", "https://example.com/shop")
+		_, err := builder.Build()
+		require.NoError(t, err)
+
+		msg, err := builder.LinkParams(map[string]string{"ref": "email"}).Build()
+		require.NoError(t, err)
+		assert.Contains(t, msg.PlainText(), "Shop (https://example.com/shop?ref=email)")
+
+		msg, err = builder.LinkParams(nil).Build()
+		require.NoError(t, err)
+		assert.Contains(t, msg.PlainText(), "Shop (https://example.com/shop)")
+	})
+
+	t.Run("params do not leak into the default builder", func(t *testing.T) {
+		originalDefault := mailgen.New()
+		defer mailgen.SetDefault(originalDefault)
+		mailgen.SetDefault(mailgen.New().Action("Shop", "https://example.com/shop"))
+
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := mailgen.New().LinkParams(utm).Build()
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		msg, err := mailgen.New().Build()
+		require.NoError(t, err)
+		assert.Contains(t, msg.PlainText(), "Shop (https://example.com/shop)")
+		assert.NotContains(t, msg.PlainText(), "utm_source")
+	})
+}
+
+func TestBuilder_RewriteLinks(t *testing.T) {
+	track := func(link string) string {
+		return "https://track.example.com/c?u=" + url.QueryEscape(link)
+	}
+	testCases := []testCase{
+		{
+			name: "links are rewritten",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					RewriteLinks(track).
+					LinkParams(map[string]string{"utm_source": "email"}).
+					Product(mailgen.Product{Name: "Shop", Link: "https://example.com"}).
+					Unsubscribe("https://example.com/unsubscribe", mailgen.UnsubscribeOption{ShowLink: true}).
+					Action("Reset", "https://app.example.com/reset").
+					Callout(mailgen.Callout{Text: "New", Link: "https://example.com/new"}).
+					Action("Email", "mailto:support@example.com")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				reset := "https://track.example.com/c?u=https%3A%2F%2Fapp.example.com%2Freset%3Futm_source%3Demail"
+				assert.Contains(t, msg.HTML(), `href="`+reset+`"`)
+				assert.Contains(t, msg.PlainText(), "Reset ("+reset+")")
+				assert.Contains(t, msg.HTML(), `href="https://track.example.com/c?u=https%3A%2F%2Fexample.com%2Fnew"`)
+				assert.Contains(t, msg.HTML(), `href="https://track.example.com/c?u=https%3A%2F%2Fexample.com%2Funsubscribe"`)
+				assert.Contains(t, msg.HTML(), `href="https://track.example.com/c?u=https%3A%2F%2Fexample.com"`)
+				assert.Contains(t, msg.HTML(), `href="mailto:support@example.com"`)
+				assert.Equal(t, []string{"<https://example.com/unsubscribe>"}, msg.Headers()["List-Unsubscribe"])
+			},
+		},
+		{
+			name: "browser, tracking and markdown links are rewritten",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					RewriteLinks(track).
+					ViewInBrowser("https://example.com/view").
+					Shipping(mailgen.ShippingConfig{
+						Carrier:        "UPS",
+						TrackingNumber: "1Z999",
+						TrackingURL:    "https://ups.example.com/track",
+					}).
+					Markdown("Read the [docs](https://example.com/docs) or [email us](mailto:support@example.com).")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `href="https://track.example.com/c?u=https%3A%2F%2Fexample.com%2Fview"`)
+				assert.Contains(t, msg.HTML(), `href="https://track.example.com/c?u=https%3A%2F%2Fups.example.com%2Ftrack"`)
+				assert.Contains(t, msg.HTML(), `href="https://track.example.com/c?u=https%3A%2F%2Fexample.com%2Fdocs"`)
+				assert.Contains(t, msg.PlainText(), "docs (https://track.example.com/c?u=https%3A%2F%2Fexample.com%2Fdocs)")
+				assert.Contains(t, msg.PlainText(), "email us (mailto:support@example.com)")
+			},
+		},
+		{
+			name: "all links are rewritten",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					RewriteLinks(track).
+					RewriteAllLinks(true).
+					Action("Email", "mailto:support@example.com")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `href="https://track.example.com/c?u=mailto%3Asupport%40example.com"`)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+
+	t.Run("building twice rewrites the links once", func(t *testing.T) {
+		builder := mailgen.New().RewriteLinks(track).Action("Reset", "https://app.example.com/reset")
+		first, err := builder.Build()
+		require.NoError(t, err)
+		second, err := builder.Build()
+		require.NoError(t, err)
+		assert.Equal(t, first.HTML(), second.HTML())
+		assert.Equal(t, first.PlainText(), second.PlainText())
+	})
+}
+
+func TestBuilder_MailtoAction(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "mailto action with subject and body",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().MailtoAction("Confirm", "confirm@example.com", mailgen.MailtoConfig{
+					Subject: "Confirm order #123",
+					Body:    "Yes, please ship it & thanks!\nJohn",
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(
+					t,
+					msg.HTML(),
+					`href="mailto:confirm@example.com?subject=Confirm%20order%20%23123&amp;body=Yes%2C%20please%20ship%20it%20%26%20thanks%21%0AJohn"`,
+				)
+				assert.Contains(t, msg.PlainText(), "Confirm (confirm@example.com)", "PlainText should show the address")
+				assert.NotContains(t, msg.PlainText(), "mailto:", "PlainText should not show the mailto URL")
+				assert.NotContains(t, msg.HTML(), "having trouble clicking", "Mailto actions should have no fallback")
+			},
+		},
+		{
+			name: "mailto action without config",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().MailtoAction("Email us", "support@example.com")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `href="mailto:support@example.com"`)
+				assert.Contains(t, msg.HTML(), "background-color:#3869D4", "HTML should use the default button color")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_Image(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "add image",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Line("Before").
+					Image(mailgen.Image{Src: "https://example.com/banner.png", Alt: "Spring sale", Width: "570"}).
+					Line("After")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(
+					t,
+					msg.HTML(),
+					`<img src="https://example.com/banner.png" alt="Spring sale" width="570"`,
+				)
+				assert.Contains(t, msg.HTML(), `style="max-width: 100%;`, "HTML should keep the image inline styles")
+				assert.Contains(t, msg.PlainText(), "Before\n\nSpring sale\n\nAfter")
+			},
+		},
+		{
+			name: "add image without src",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Image(mailgen.Image{Alt: "Missing"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), "<img", "HTML should not contain an image without src")
+				assert.NotContains(t, msg.PlainText(), "Missing")
+			},
+		},
+		{
+			name: "add inline CID images",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Image(mailgen.Image{CID: "logo", Src: "https://example.com/logo.png", Alt: "Logo"}).
+					Image(mailgen.Image{CID: "banner"}).
+					Image(mailgen.Image{CID: "logo"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `<img src="cid:logo" alt="Logo"`, "HTML should reference the Content-ID")
+				assert.NotContains(t, msg.HTML(), "https://example.com/logo.png", "Src should be ignored when CID is set")
+				assert.Contains(t, msg.HTML(), `<img src="cid:banner"`)
+				assert.Equal(t, []string{"logo", "banner"}, msg.ContentIDs())
+				assert.NotContains(t, msg.PlainText(), "cid:", "PlainText should not contain Content-IDs")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_Title(t *testing.T) {
+	for _, theme := range []string{"default", "plain"} {
+		msg, err := mailgen.New().
+			Theme(theme).
+			Line("Thanks for your purchase.").
+			Title("Your receipt & <summary>").
+			Line("Order #123").
+			Title("").
+			Build()
+		require.NoError(t, err)
+
+		assert.Regexp(t, `</p><h1 class="title" style="[^"]*font-size:28px[^"]*">Your receipt &amp; &lt;summary&gt;</h1><p`,
+			msg.HTML(), "Title should be rendered where it is placed")
+		assert.Equal(t, 1, strings.Count(msg.HTML(), `class="title"`), "Empty titles should be ignored")
+		assert.Contains(t, msg.PlainText(), "Thanks for your purchase.\n\nYOUR RECEIPT & <SUMMARY>\n\nOrder #123")
+	}
+}
+
+func TestBuilder_Section(t *testing.T) {
+	for _, theme := range []string{"default", "plain"} {
+		msg, err := mailgen.New().
+			Theme(theme).
+			Title("Monthly summary").
+			Section("Usage").
+			Line("You sent 1,204 emails.").
+			Section("").
+			Build()
+		require.NoError(t, err)
+
+		assert.Regexp(t, `</h1><h2 class="section" style="[^"]*font-size:18px[^"]*">Usage</h2><p`, msg.HTML())
+		assert.Equal(t, 1, strings.Count(msg.HTML(), `class="section"`), "Empty sections should be ignored")
+		assert.Contains(t, msg.PlainText(), "MONTHLY SUMMARY\n\nUsage\n=====\n\nYou sent 1,204 emails.")
+	}
+}
+
+func TestBuilder_Outro(t *testing.T) {
+	for _, theme := range []string{"default", "plain"} {
+		msg, err := mailgen.New().
+			Theme(theme).
+			Line("Click the button below to reset your password").
+			Action("Reset Password", "https://example.com/reset-password").
+			Outro("If you did not request a password reset, no further action is required.").
+			Build()
+		require.NoError(t, err)
+
+		assert.Regexp(
+			t,
+			`</table><p class="outro" style="[^"]*font-size:14px[^"]*">If you did not request a password reset`,
+			msg.HTML(),
+		)
+		assert.Equal(t, 1, strings.Count(msg.HTML(), `class="outro"`), "Only outro lines should be muted")
+		assert.Contains(
+			t,
+			msg.PlainText(),
+			"https://example.com/reset-password)\n\nIf you did not request a password reset, no further action is required.",
+		)
+	}
+}
+
+func TestBuilder_When(t *testing.T) {
+	addGift := func(b *mailgen.Builder) {
+		b.Line("It is wrapped as a gift.")
+	}
+	testCases := []testCase{
+		{
+			name: "when true and unless false add content",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Line("Your order has shipped.").
+					When(true, addGift).
+					Unless(false, func(b *mailgen.Builder) {
+						b.Line("Delivery is free.")
+					}).
+					Line("Thank you!")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Regexp(t, `(?s)Your order has shipped\..*It is wrapped as a gift\..*Delivery is free\..*Thank you!`,
+					msg.PlainText())
+			},
+		},
+		{
+			name: "when false and unless true add nothing",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Line("Your order has shipped.").
+					When(false, addGift).
+					Unless(true, addGift).
+					When(true, nil)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "Your order has shipped.")
+				assert.NotContains(t, msg.PlainText(), "gift")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_InsertComponent(t *testing.T) {
+	msg, err := mailgen.New().
+		Line("Second").
+		Line("Fourth").
+		InsertComponent(1, mailgen.Line{Text: "Third"}).
+		PrependLine("First").
+		InsertComponent(-5, mailgen.Line{Text: "Zeroth"}).
+		InsertComponent(100, mailgen.Line{Text: "Fifth"}).
+		InsertComponent(0, nil).
+		Build()
+	require.NoError(t, err)
+
+	assert.Contains(t, msg.PlainText(), "Zeroth\n\nFirst\n\nSecond\n\nThird\n\nFourth\n\nFifth")
+	assert.Regexp(t, `(?s)<p[^>]*>Zeroth</p>\s*<p[^>]*>First</p>.*<p[^>]*>Fifth</p>`, msg.HTML())
+}
+
+func TestBuilder_Alert(t *testing.T) {
+	for _, theme := range []string{"default", "plain"} {
+		msg, err := mailgen.New().
+			Theme(theme).
+			Alert(mailgen.AlertWarning, "Your password was changed").
+			Alert(mailgen.AlertWarning, "").
+			Line("If you did not make this change, please contact support immediately.").
+			Build()
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, strings.Count(msg.HTML(), `class="alert"`), "Empty alerts should be ignored")
+		assert.Contains(t, msg.HTML(), `bgcolor="#FEF6E7"`)
+		assert.Regexp(t, `class="alert_content"[^>]*style="[^"]*background-color:#FEF6E7[^"]*`+
+			`border-left:4px solid #F5A623`, msg.HTML())
+		assert.Contains(t, msg.PlainText(), "[WARNING] Your password was changed\n\nIf you did not make this change")
+	}
+}
+
+func TestBuilder_Divider(t *testing.T) {
+	for _, theme := range []string{"default", "plain"} {
+		msg, err := mailgen.New().
+			Theme(theme).
+			Line("First section").
+			Divider().
+			Line("Second section").
+			Build()
+		require.NoError(t, err)
+
+		assert.Contains(t, msg.HTML(), `class="divider"`, "HTML should contain the divider")
+		assert.Contains(t, msg.PlainText(), "First section\n\n"+strings.Repeat("-", 40)+"\n\nSecond section")
+	}
+
+	defaultMsg, err := mailgen.New().Divider().Build()
+	require.NoError(t, err)
+	assert.Contains(
+		t,
+		defaultMsg.HTML(),
+		"padding:12px 0 0;border-top:1px solid #EAEAEC",
+		"Default theme should render the divider as a rule",
+	)
+
+	plainMsg, err := mailgen.New().Theme("plain").Divider().Build()
+	require.NoError(t, err)
+	assert.NotContains(
+		t,
+		plainMsg.HTML(),
+		"padding:12px 0 0;border-top:1px solid #EAEAEC",
+		"Plain theme should render the divider as whitespace",
+	)
+}
+
+func TestBuilder_Coupon(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "add coupon with description",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Coupon("SAVE10", mailgen.CouponConfig{Description: "10% off your next order"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "border:2px dashed #CBCCCF", "HTML should render a dashed box")
+				assert.Contains(t, msg.HTML(), ">SAVE10</span>")
+				assert.Contains(t, msg.HTML(), "10% off your next order")
+				assert.Contains(t, msg.PlainText(), "**********\n[ SAVE10 ]\n**********\n10% off your next order")
+			},
+		},
+		{
+			name: "add coupon with empty code",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Coupon("")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), `class="discount"`, "HTML should not contain an empty coupon")
+				assert.NotContains(t, msg.PlainText(), "[  ]")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_Shipping(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "add shipping with all details",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Shipping(mailgen.ShippingConfig{
+					Carrier:           "UPS",
+					TrackingNumber:    "1Z999AA10123456784",
+					TrackingURL:       "https://example.com/track/1Z999AA10123456784",
+					EstimatedDelivery: time.Date(2024, time.January, 9, 0, 0, 0, 0, time.UTC),
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Arrives Tuesday, Jan 9")
+				assert.Contains(t, msg.HTML(), "UPS")
+				assert.Contains(
+					t,
+					msg.HTML(),
+					`href="https://example.com/track/1Z999AA10123456784"`,
+					"HTML should link the tracking number",
+				)
 				assert.Contains(
 					t,
 					msg.PlainText(),
-					"https://customfallback.com",
-					"PlainText should contain the action URL",
+					"Arrives Tuesday, Jan 9\nCarrier: UPS\n"+
+						"Tracking number: 1Z999AA10123456784 (https://example.com/track/1Z999AA10123456784)",
 				)
 			},
 		},
+		{
+			name: "add shipping with custom date format",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Shipping(mailgen.ShippingConfig{
+					EstimatedDelivery: time.Date(2024, time.January, 9, 0, 0, 0, 0, time.UTC),
+					DateFormat:        "02/01/2006",
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "Arrives 09/01/2024")
+				assert.NotContains(t, msg.PlainText(), "Carrier:")
+			},
+		},
+		{
+			name: "add shipping without details",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Shipping(mailgen.ShippingConfig{DateFormat: "02/01/2006"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), `class="attributes"`, "HTML should not contain an empty shipping block")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_AutoPlainText(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "raw HTML only without auto plain text",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Bare().
+					RawHTML(`<h2>Weekly digest</h2><p>Read <a href="https://example.com/posts">the posts</a>.</p>`)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `>Weekly digest</h2>`, "HTML should contain the raw HTML unescaped")
+				assert.Empty(t, msg.PlainText(), "PlainText should be empty without AutoPlainText")
+			},
+		},
+		{
+			name: "raw HTML only with auto plain text",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					RawHTML(`<h2>Weekly digest</h2><p>Read <a href="https://example.com/posts">the posts</a>.</p>`).
+					AutoPlainText()
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				text := msg.PlainText()
+				assert.Contains(t, text, "Hi,")
+				assert.Contains(t, text, "Weekly digest")
+				assert.Contains(t, text, "Read the posts ( https://example.com/posts ).")
+				assert.Contains(t, text, "Best regards,")
+				assert.NotContains(t, text, "<p>", "PlainText should not contain HTML tags")
+				assert.NotContains(t, text, "color-scheme", "PlainText should not contain styles")
+			},
+		},
+		{
+			name: "empty raw HTML",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Bare().RawHTML("")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Empty(t, msg.PlainText())
+			},
+		},
 	}
 	for _, tc := range testCases {
 		tc.run(t)
 	}
 }
 
-func TestBuilder_PostmarkCompatibilityMarkers(t *testing.T) {
-	for _, theme := range []string{"default", "plain"} {
-		baseMsg, err := mailgen.New().
-			Theme(theme).
-			Action("Click Here", "https://example.com").
-			Build()
-		require.NoError(t, err)
+func TestBuilder_PlainTextNewlineMode(t *testing.T) {
+	newBuilder := func() *mailgen.Builder {
+		return mailgen.New().
+			Bare().
+			Line("First paragraph\n\n\n\nSecond paragraph\n  \nThird paragraph").
+			Line("Last line")
+	}
+	tests := []struct {
+		name     string
+		mode     string
+		expected string
+	}{
+		{
+			name:     "default mode",
+			expected: "First paragraph\n\nSecond paragraph\n  \nThird paragraph\n\nLast line",
+		},
+		{
+			name:     "compact mode",
+			mode:     mailgen.NewlineCompact,
+			expected: "First paragraph\n\nSecond paragraph\n  \nThird paragraph\n\nLast line",
+		},
+		{
+			name:     "single mode",
+			mode:     mailgen.NewlineSingle,
+			expected: "First paragraph\nSecond paragraph\nThird paragraph\nLast line",
+		},
+		{
+			name:     "preserve mode",
+			mode:     mailgen.NewlinePreserve,
+			expected: "First paragraph\n\n\n\nSecond paragraph\n  \nThird paragraph\n\nLast line",
+		},
+		{
+			name:     "invalid mode keeps default",
+			mode:     "invalid",
+			expected: "First paragraph\n\nSecond paragraph\n  \nThird paragraph\n\nLast line",
+		},
+	}
 
-		assert.Contains(
-			t,
-			baseMsg.HTML(),
-			`style="color-scheme: light dark; supported-color-schemes: light dark;"`,
-			"HTML root should include color scheme metadata style",
-		)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBuilder()
+			if tt.mode != "" {
+				b.PlainTextNewlineMode(tt.mode)
+			}
+			msg, err := b.Build()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, msg.PlainText())
+		})
+	}
+}
 
-		greenMsg, err := mailgen.New().
-			Theme(theme).
-			Action("Green", "https://example.com", mailgen.Action{Color: "#22BC66"}).
-			Build()
-		require.NoError(t, err)
-		assert.Contains(
-			t,
-			greenMsg.HTML(),
-			`class="f-fallback button button--green"`,
-			"HTML should include Postmark-compatible green button class in action link",
-		)
+func TestBuilder_List(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "add bulleted list",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().List([]string{"Complete your profile", "Invite <your> team"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "<ul")
+				assert.Contains(t, msg.HTML(), "Invite &lt;your&gt; team", "HTML should escape list items")
+				assert.Contains(t, msg.PlainText(), "- Complete your profile\n- Invite <your> team")
+			},
+		},
+		{
+			name: "add numbered list",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().OrderedList([]string{"Download the app", "Sign in"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "<ol")
+				assert.Contains(t, msg.PlainText(), "1. Download the app\n2. Sign in")
+			},
+		},
+		{
+			name: "add empty list",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().List(nil).OrderedList([]string{})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), "<ul")
+				assert.NotContains(t, msg.HTML(), "<ol")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
 
-		redMsg, err := mailgen.New().
-			Theme(theme).
-			Action("Red", "https://example.com", mailgen.Action{Color: "#FF6136"}).
-			Build()
-		require.NoError(t, err)
-		assert.Contains(
-			t,
-			redMsg.HTML(),
-			`class="f-fallback button button--red"`,
-			"HTML should include Postmark-compatible red button class in action link",
-		)
+func TestBuilder_Callout(t *testing.T) {
+	msg, err := mailgen.New().
+		Callout(mailgen.Callout{
+			Text:     "Your subscription expires in 3 days.",
+			Link:     "https://example.com/billing",
+			LinkText: "Renew now",
+		}).
+		Build()
+	require.NoError(t, err)
+
+	assert.Contains(t, msg.HTML(), "Your subscription expires in 3 days.")
+	assert.Contains(t, msg.HTML(), "border-left:4px solid #3869D4", "HTML should use the default callout color")
+	assert.Contains(t, msg.PlainText(), "Your subscription expires in 3 days.\nRenew now: https://example.com/billing")
+}
+
+func TestBuilder_SecurityNotice(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "default security notice is prepended",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Line("Click the button below to reset your password").
+					SecurityNotice("https://example.com/support")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				notice := "If you didn't request this, your account may be compromised."
+				assert.Contains(t, msg.PlainText(), notice)
+				assert.Contains(t, msg.PlainText(), "Contact support: https://example.com/support")
+				assert.Less(
+					t,
+					strings.Index(msg.PlainText(), notice),
+					strings.Index(msg.PlainText(), "Click the button below"),
+					"Security notice should be rendered before other components",
+				)
+				assert.Contains(t, msg.HTML(), `href="https://example.com/support"`)
+				assert.Contains(t, msg.HTML(), "border-left:4px solid #FF6136")
+			},
+		},
+		{
+			name: "custom security notice text",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().SecurityNotice("https://example.com/support", "Not you? Let us know.")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "Not you? Let us know.")
+				assert.Contains(t, msg.PlainText(), "Not you? Let us know.")
+				assert.NotContains(t, msg.PlainText(), "your account may be compromised")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
 	}
 }
 
-func TestBuilder_UsePremailer(t *testing.T) {
-	t.Run("enabled by default", func(t *testing.T) {
-		msg, err := mailgen.New().
-			Line("Hello").
-			Build()
-		require.NoError(t, err)
+func TestBuilder_ContentHTML(t *testing.T) {
+	content, err := mailgen.New().
+		Greeting("Hello").
+		Line("Your order has shipped.").
+		Action("Track Order", "https://example.com/track").
+		ContentHTML()
+	require.NoError(t, err)
 
-		assert.Contains(t, msg.HTML(), `style="`, "HTML should include inline styles when premailer is enabled")
-	})
+	assert.Contains(t, string(content), "<p>Your order has shipped.</p>")
+	assert.Contains(t, string(content), `href="https://example.com/track"`)
+	assert.NotContains(t, string(content), "<html", "Content should not contain the full document")
+	assert.NotContains(t, string(content), "Hello", "Content should not contain the greeting")
 
-	t.Run("disabled should keep template classes", func(t *testing.T) {
-		msg, err := mailgen.New().
-			UsePremailer(false).
-			Line("Hello").
-			Build()
-		require.NoError(t, err)
+	parent := htmltemplate.Must(htmltemplate.New("parent").Parse(`<main>{{.EmailContent}}</main>`))
+	var buf bytes.Buffer
+	require.NoError(t, parent.Execute(&buf, map[string]any{"EmailContent": content}))
 
-		assert.Contains(
-			t,
-			msg.HTML(),
-			`class="email-wrapper"`,
-			"HTML should keep template classes when premailer is disabled",
-		)
-		assert.NotContains(
-			t,
-			msg.HTML(),
-			`style="height:100%;margin:0;`,
-			"HTML body should not be inlined when premailer is disabled",
-		)
-	})
+	assert.True(
+		t,
+		strings.HasPrefix(buf.String(), "<main><p>Your order has shipped.</p>"),
+		"Content should be embedded without escaping",
+	)
+	assert.NotContains(t, buf.String(), "&lt;p&gt;")
+
+	content, err = mailgen.New().
+		LinkParams(map[string]string{"utm_source": "email"}).
+		RewriteLinks(func(link string) string { return "https://track.example.com/c?u=" + url.QueryEscape(link) }).
+		Action("Track Order", "https://example.com/track").
+		Action("Call us", "tel:+15555550100").
+		ContentHTML()
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `href="https://track.example.com/c?u=https%3A%2F%2Fexample.com%2Ftrack%3Futm_source%3Demail"`)
+	assert.Contains(t, string(content), `href="#"`, "Content should have its links sanitized")
 }
 
 func TestBuilder_Product(t *testing.T) {
+	clock := func() time.Time { return time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC) }
 	testCases := []testCase{
 		{
 			name: "set product with complete info",
@@ -748,13 +3223,13 @@ func TestBuilder_Product(t *testing.T) {
 		{
 			name: "set product with only name",
 			builderFunc: func() *mailgen.Builder {
-				return mailgen.New().Product(mailgen.Product{
+				return mailgen.New().Clock(clock).Product(mailgen.Product{
 					Name: "Test Product",
 				})
 			},
 			expectError: false,
 			expectFunc: func(msg mailgen.Message) {
-				defaultCopyright := fmt.Sprintf("© %d Test Product. All rights reserved.", time.Now().Year())
+				defaultCopyright := "© 2025 Test Product. All rights reserved."
 				assert.Contains(t, msg.HTML(), "Test Product", "HTML should contain the product name")
 				assert.Contains(t, msg.HTML(), defaultCopyright, "HTML should contain the default product copyright")
 				assert.Contains(t, msg.PlainText(), "Test Product", "PlainText should contain the product name")
@@ -766,6 +3241,35 @@ func TestBuilder_Product(t *testing.T) {
 				)
 			},
 		},
+		{
+			name: "logo alt defaults to the product name",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Product(mailgen.Product{Name: "Acme", Logo: "https://example.com/logo.png"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `<img src="https://example.com/logo.png" class="email-masthead_logo" alt="Acme"`)
+				assert.Regexp(t, `class="email-masthead_logo" alt="Acme" style="[^"]*max-height:50px`, msg.HTML())
+			},
+		},
+		{
+			name: "set logo alt and dimensions",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Product(mailgen.Product{
+					Name:       "Acme",
+					Logo:       "https://example.com/logo.png",
+					LogoAlt:    "Acme Inc.",
+					LogoWidth:  "120",
+					LogoHeight: "80",
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `alt="Acme Inc." width="120" height="80"`)
+				assert.Regexp(t, `height="80" style="[^"]*max-height:none`, msg.HTML(),
+					"The logo height should not be capped when it is set")
+			},
+		},
 		{
 			name: "set product with only copyright",
 			builderFunc: func() *mailgen.Builder {
@@ -797,6 +3301,227 @@ func TestBuilder_Product(t *testing.T) {
 				)
 			},
 		},
+		{
+			name: "set product with custom copyright suffix",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Clock(clock).
+					Product(mailgen.Product{Name: "Test Product"}).
+					CopyrightSuffix("Alle Rechte vorbehalten.")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				copyright := "© 2025 Test Product. Alle Rechte vorbehalten."
+				assert.Contains(t, msg.HTML(), copyright, "HTML should contain the custom copyright suffix")
+				assert.Contains(t, msg.PlainText(), copyright, "PlainText should contain the custom copyright suffix")
+				assert.NotContains(t, msg.PlainText(), "All rights reserved.")
+			},
+		},
+		{
+			name: "set product with empty copyright suffix",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Clock(clock).
+					CopyrightSuffix("").
+					Product(mailgen.Product{Name: "Test Product"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				copyright := "© 2025 Test Product."
+				assert.Contains(t, msg.PlainText(), copyright, "PlainText should contain the copyright without suffix")
+				assert.NotContains(t, msg.HTML(), "All rights reserved.", "HTML should not contain the default suffix")
+				assert.NotContains(t, msg.PlainText(), "All rights reserved.")
+			},
+		},
+		{
+			name: "copyright suffix does not affect explicit copyright",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					CopyrightSuffix("Custom suffix.").
+					Product(mailgen.Product{Name: "Test Product", Copyright: "© 2023 Test Product"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "© 2023 Test Product")
+				assert.NotContains(t, msg.PlainText(), "Custom suffix.")
+			},
+		},
+		{
+			name: "set product with copyright start year",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Clock(clock).Product(mailgen.Product{Name: "Acme", CopyrightStartYear: 2019})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				copyright := "© 2019–2025 Acme. All rights reserved."
+				assert.Contains(t, msg.HTML(), copyright, "HTML should contain the copyright year range")
+				assert.Contains(t, msg.PlainText(), copyright, "PlainText should contain the copyright year range")
+			},
+		},
+		{
+			name: "copyright start year in the current year renders a single year",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Clock(clock).Product(mailgen.Product{Name: "Acme", CopyrightStartYear: 2025})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				copyright := "© 2025 Acme. All rights reserved."
+				assert.Contains(t, msg.PlainText(), copyright)
+			},
+		},
+		{
+			name: "set product without copyright suffix",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Clock(clock).Product(mailgen.Product{
+					Name:               "Acme",
+					CopyrightStartYear: 2019,
+					NoCopyrightSuffix:  true,
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				copyright := "© 2019–2025 Acme."
+				assert.Contains(t, msg.PlainText(), copyright)
+				assert.NotContains(t, msg.HTML(), "All rights reserved.", "HTML should not contain the suffix")
+				assert.NotContains(t, msg.PlainText(), "All rights reserved.")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+
+	t.Run("nil clock restores the current time", func(t *testing.T) {
+		msg, err := mailgen.New().Clock(clock).Clock(nil).Product(mailgen.Product{Name: "Acme"}).Build()
+		require.NoError(t, err)
+		assert.Contains(t, msg.PlainText(), fmt.Sprintf("© %d Acme.", time.Now().Year()))
+	})
+}
+
+func TestBuilder_Social(t *testing.T) {
+	links := []mailgen.SocialLink{
+		{Name: "Twitter", URL: "https://twitter.com/example"},
+		{Name: "LinkedIn", URL: "https://linkedin.com/company/example", IconURL: "https://example.com/linkedin.png"},
+		{Name: "Empty"},
+	}
+	testCases := []testCase{
+		{
+			name: "set social links",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Social(links)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				html := msg.HTML()
+				assert.Contains(t, html, `class="social"`, "HTML should contain the social row")
+				assert.Contains(t, html, `>Twitter</a>`, "HTML should render a text link without an icon")
+				assert.Contains(t, html, `src="https://example.com/linkedin.png"`, "HTML should render the icon")
+				assert.Contains(t, html, `alt="LinkedIn"`)
+				assert.Less(
+					t,
+					strings.Index(html, "All rights reserved."),
+					strings.Index(html, `class="social"`),
+					"Social links should appear below the copyright",
+				)
+				assert.NotContains(t, html, "Empty", "Links without a URL should be skipped")
+
+				text := msg.PlainText()
+				assert.Contains(
+					t,
+					text,
+					"All rights reserved.\n\nTwitter: https://twitter.com/example\nLinkedIn: https://linkedin.com/company/example",
+				)
+			},
+		},
+		{
+			name:        "no social links",
+			builderFunc: mailgen.New,
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), `class="social"`, "HTML should not contain an empty social row")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+
+	t.Run("social links are cloned from the default", func(t *testing.T) {
+		originalDefault := mailgen.New()
+		defer mailgen.SetDefault(originalDefault)
+
+		mailgen.SetDefault(mailgen.New().Social(links[:1]))
+		builder := mailgen.New()
+		mailgen.SetDefault(mailgen.New().Social(links[1:2]))
+
+		msg, err := builder.Build()
+		require.NoError(t, err)
+		assert.Contains(t, msg.PlainText(), "Twitter: https://twitter.com/example")
+		assert.NotContains(t, msg.PlainText(), "LinkedIn")
+	})
+}
+
+func TestBuilder_DigestItem(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "multiple digest items",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Line("Here is what happened today.").
+					DigestItem("New comment", func(item *mailgen.Builder) {
+						item.Line("Jane commented on your post.").
+							Action("View comment", "https://example.com/comments/1")
+					}).
+					DigestItem("New follower", func(item *mailgen.Builder) {
+						item.Line("John started following you.")
+					})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, 2, strings.Count(msg.HTML(), `class="digest_item"`))
+				assert.Contains(t, msg.HTML(), ">New comment</h3>")
+				assert.Contains(t, msg.HTML(), ">New follower</h3>")
+				assert.Contains(t, msg.HTML(), ">Jane commented on your post.</p>")
+				assert.Contains(t, msg.HTML(), `href="https://example.com/comments/1"`)
+				assert.Contains(
+					t,
+					msg.HTML(),
+					"If you&#39;re having trouble clicking the",
+					"HTML should contain the fallback of the nested action",
+				)
+				assert.Contains(
+					t,
+					msg.PlainText(),
+					"New comment\n===========\n\nJane commented on your post.\n\nView comment (https://example.com/comments/1)",
+				)
+				assert.Contains(t, msg.PlainText(), "New follower\n============\n\nJohn started following you.")
+			},
+		},
+		{
+			name: "digest item with fallback after action",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					FallbackPlacement(mailgen.FallbackAfterAction).
+					DigestItem("New comment", func(item *mailgen.Builder) {
+						item.Action("View comment", "https://example.com/comments/1")
+					})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "If you&#39;re having trouble clicking the")
+			},
+		},
+		{
+			name: "digest item without builder func",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().DigestItem("Nothing new", nil)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), ">Nothing new</h3>")
+				assert.Contains(t, msg.PlainText(), "Nothing new\n===========")
+			},
+		},
 	}
 	for _, tc := range testCases {
 		tc.run(t)
@@ -854,6 +3579,228 @@ func TestBuilder_Table(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "table cells with custom align and width",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Table(mailgen.Table{
+					Data: [][]mailgen.Entry{
+						{{Key: "Item", Value: "Golang"}, {Key: "Price", Value: "$10.99"}},
+					},
+					Footer: []mailgen.Entry{{Key: "Price", Value: "$10.99"}},
+					Columns: mailgen.Columns{
+						CustomAlign: map[string]string{"Price": "right"},
+						CustomWidth: map[string]string{"Price": "20%"},
+					},
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Regexp(t, `<th width="20%" align="right" style="[^"]*text-align:right;width:20%"`, msg.HTML())
+				assert.Regexp(t, `<td class="align-right" style="[^"]*text-align:right;width:20%"><span`, msg.HTML())
+				assert.Regexp(t, `<td class="data-table-footer align-right" style="[^"]*text-align:right;width:20%"`, msg.HTML())
+				assert.Regexp(t, `<td class="align-left" style="[^"]*text-align:left"><span`, msg.HTML())
+			},
+		},
+		{
+			name: "table with header alignment",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Table(mailgen.Table{
+					Data: [][]mailgen.Entry{
+						{{Key: "Item", Value: "Golang"}, {Key: "Price", Value: "$10.99"}},
+					},
+					Columns: mailgen.Columns{
+						CustomAlign: map[string]string{"Item": "center", "Price": "right"},
+						HeaderAlign: map[string]string{"Item": "left"},
+					},
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Regexp(t, `<th width="auto" align="left" style="[^"]*text-align:left"><p[^>]*>Item</p>`, msg.HTML())
+				assert.Regexp(t, `<th width="auto" align="right" style="[^"]*text-align:right"><p[^>]*>Price</p>`, msg.HTML())
+				assert.Regexp(t, `<td class="align-center" style="[^"]*text-align:center"><span[^>]*>Golang</span>`, msg.HTML())
+				assert.Contains(t, msg.PlainText(), "Item   |  Price\n")
+			},
+		},
+		{
+			name: "table with caption",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Table(mailgen.Table{
+					Caption: "Order #12345 details",
+					Data: [][]mailgen.Entry{
+						{{Key: "Item", Value: "Golang"}},
+					},
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Regexp(
+					t,
+					`<table class="data-table-content"[^>]*><caption class="data-table-caption" style="[^"]*text-align:left">Order #12345 details</caption>`,
+					msg.HTML(),
+				)
+				assert.Contains(t, msg.PlainText(), "Order #12345 details\n\nItem  \n")
+			},
+		},
+		{
+			name: "striped table",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Table(mailgen.Table{
+					Data: [][]mailgen.Entry{
+						{{Key: "Item", Value: "Golang"}},
+						{{Key: "Item", Value: "Mailgen"}},
+						{{Key: "Item", Value: "Premailer"}},
+						{{Key: "Item", Value: "Testify"}},
+					},
+					Striped: true,
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, 2, strings.Count(msg.HTML(), `<tr class="data-table_striped">`))
+				assert.Regexp(t, `<tr class="data-table_striped"><td class="align-left" style="[^"]*background-color:#F4F4F7`, msg.HTML())
+				assert.NotContains(t, msg.PlainText(), "striped")
+			},
+		},
+		{
+			name: "table with headers",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Table(mailgen.Table{
+					Data: [][]mailgen.Entry{
+						{{Key: "item", Value: "Golang"}, {Key: "price", Value: "$10.99"}},
+					},
+					Headers: []string{"Product", "Unit Price"},
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), ">Product</p></th>")
+				assert.Contains(t, msg.HTML(), ">Unit Price</p></th>")
+				assert.NotContains(t, msg.HTML(), ">Price</p></th>")
+				assert.Contains(t, msg.PlainText(), "Product | Unit Price\n")
+			},
+		},
+		{
+			name: "table with footer",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Table(mailgen.Table{
+					Data: [][]mailgen.Entry{
+						{{Key: "Item", Value: "Golang"}, {Key: "Count", Value: "2"}, {Key: "Price", Value: "$21.98"}},
+						{{Key: "Item", Value: "Mailgen"}, {Key: "Count", Value: "1"}, {Key: "Price", Value: "$1.99"}},
+					},
+					Footer: []mailgen.Entry{{Key: "Item", Value: "Total"}, {Key: "Price", Value: "$23.97"}},
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, 3, strings.Count(msg.HTML(), `class="data-table-footer`))
+				assert.Contains(t, msg.HTML(), "<strong>Total</strong>")
+				assert.Contains(t, msg.HTML(), "<strong>$23.97</strong>")
+				assert.Contains(t, msg.HTML(), "border-top:1px solid #EAEAEC")
+				assert.Contains(t, msg.PlainText(), "Mailgen | 1     | $1.99 \n--------+-------+-------\nTotal   |       | $23.97")
+			},
+		},
+		{
+			name: "table with max cell width",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Table(mailgen.Table{
+					Data: [][]mailgen.Entry{
+						{
+							{Key: "Item", Value: "Golang"},
+							{Key: "Description", Value: "An open source programming language supported by Google."},
+						},
+					},
+					Columns: mailgen.Columns{
+						MaxCellWidth: map[string]int{"Description": 20},
+					},
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), "max-width: 20ch")
+				assert.Contains(t, msg.HTML(), "text-overflow: ellipsis")
+				assert.Contains(
+					t,
+					msg.HTML(),
+					">An open source programming language supported by Google.</span>",
+					"HTML should keep the full value for clients without CSS support",
+				)
+				assert.Contains(t, msg.PlainText(), "An open source pr...")
+				assert.NotContains(t, msg.PlainText(), "programming language")
+			},
+		},
+		{
+			name: "table with sum and currency columns",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Table(mailgen.Table{
+					Data: [][]mailgen.Entry{
+						{{Key: "Item", Value: "Golang"}, {Key: "Price", Value: "1099"}},
+						{{Key: "Item", Value: "Mailgen"}, {Key: "Price", Value: "1.5"}},
+					},
+					Footer:     []mailgen.Entry{{Key: "Item", Value: "Total"}},
+					SumColumns: []string{"Price"},
+					Columns: mailgen.Columns{
+						Currency: map[string]string{"Price": "USD"},
+					},
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Regexp(t, `<td class="align-right" style="[^"]*text-align:right"><span[^>]*>\$1,099.00</span>`, msg.HTML())
+				assert.Contains(t, msg.HTML(), "<strong>$1,100.50</strong>")
+				assert.Contains(t, msg.PlainText(), "--------+----------\nTotal   | $1,100.50\n")
+			},
+		},
+		{
+			name: "table with strict sum and non-numeric value",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Table(mailgen.Table{
+					Data: [][]mailgen.Entry{
+						{{Key: "Item", Value: "Golang"}, {Key: "Price", Value: "$10.99"}},
+					},
+					SumColumns: []string{"Price"},
+					StrictSum:  true,
+				})
+			},
+			expectError: true,
+		},
+		{
+			name: "table with full-width row",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Table(mailgen.Table{
+					Data: [][]mailgen.Entry{
+						{{Key: "Item", Value: "Golang"}, {Key: "Count", Value: "2"}, {Key: "Price", Value: "$21.98"}},
+						{{Key: "Item", Value: "Thank you for your business", Span: 3}},
+					},
+					Footer: []mailgen.Entry{{Key: "Item", Value: "Total", Span: 2}, {Key: "Price", Value: "$21.98"}},
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Regexp(t, `<td colspan="3" class="align-left"[^>]*><span[^>]*>Thank you for your business</span>`, msg.HTML())
+				assert.Regexp(t, `<td colspan="2" class="data-table-footer align-left"[^>]*><span[^>]*><strong>Total</strong>`, msg.HTML())
+				assert.Equal(t, 2, strings.Count(msg.HTML(), `class="data-table-footer`))
+				assert.Contains(t, msg.PlainText(), "\nThank you for your business\n")
+				assert.Contains(t, msg.PlainText(), "\nTotal          | $21.98\n")
+			},
+		},
+		{
+			name: "table with percentage widths in wrapped plain text",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().WrapText(43).Table(mailgen.Table{
+					Data: [][]mailgen.Entry{
+						{{Key: "Item", Value: "Golang"}, {Key: "Price", Value: "$10.99"}},
+					},
+					Columns: mailgen.Columns{
+						CustomWidth: map[string]string{"Item": "50%", "Price": "50%"},
+					},
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "\nItem                 | Price               \n")
+			},
+		},
 		{
 			name: "table with no data",
 			builderFunc: func() *mailgen.Builder {
@@ -1045,6 +3992,39 @@ func TestBuilder_Build(t *testing.T) {
 	}
 }
 
+func TestBuilder_Render(t *testing.T) {
+	builders := map[string]func() *mailgen.Builder{
+		"default theme": func() *mailgen.Builder {
+			return mailgen.New().Name("John").Line("Your order has shipped.").Action("Track", "https://example.com")
+		},
+		"plain theme with auto plain text": func() *mailgen.Builder {
+			return mailgen.New().Theme("plain").AutoPlainText().Line("Your order has shipped.")
+		},
+	}
+	for name, builderFunc := range builders {
+		t.Run(name, func(t *testing.T) {
+			msg, err := builderFunc().Build()
+			require.NoError(t, err)
+
+			html, text, err := builderFunc().Render()
+			require.NoError(t, err)
+			assert.Equal(t, msg.HTML(), html)
+			assert.Equal(t, msg.PlainText(), text)
+		})
+	}
+
+	t.Run("addresses are not validated", func(t *testing.T) {
+		html, _, err := mailgen.New().StrictAddresses(true).To("invalid").Line("Hello").Render()
+		require.NoError(t, err)
+		assert.Contains(t, html, "Hello")
+	})
+
+	t.Run("invalid links are rejected", func(t *testing.T) {
+		_, _, err := mailgen.New().StrictLinks(true).Action("Open", "javascript:alert(1)").Render()
+		require.ErrorIs(t, err, mailgen.ErrInvalidLink)
+	})
+}
+
 func TestBuilder_TextDirection(t *testing.T) {
 	testCases := []testCase{
 		{
@@ -1122,6 +4102,30 @@ func TestBuilder_TextDirection(t *testing.T) {
 				)
 			},
 		},
+		{
+			name: "rtl text direction affects table layout",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().TextDirection("rtl").Table(mailgen.Table{
+					Headers: []string{"المنتج", "السعر"},
+					Data: [][]mailgen.Entry{
+						{{Key: "item", Value: "قلم"}, {Key: "price", Value: "10"}},
+						{{Key: "item", Value: "كتاب"}, {Key: "price", Value: "250"}},
+					},
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `<table class="data-table" dir="rtl"`)
+				assert.Regexp(t, `<td class="align-right" style="[^"]*text-align:right"><span class="f-fallback">قلم`, msg.HTML())
+				assert.NotContains(t, msg.HTML(), `class="align-left"`, "Cells should default to right alignment")
+				assert.Contains(
+					t,
+					msg.PlainText(),
+					"السعر | المنتج\n------+-------\n   10 |    قلم\n  250 |   كتاب\n",
+					"PlainText should render columns in reverse order aligned to the right",
+				)
+			},
+		},
 		{
 			name: "ltr text direction affects greeting line order",
 			builderFunc: func() *mailgen.Builder {
@@ -1144,3 +4148,216 @@ func TestBuilder_TextDirection(t *testing.T) {
 		tc.run(t)
 	}
 }
+
+func TestBuilder_Language(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "set language",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Language("pt_BR").AMP(true)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `<html lang="pt-BR" xmlns=`)
+				assert.Contains(t, msg.AMPHTML(), `<html ⚡4email data-css-strict lang="pt-BR">`)
+			},
+		},
+		{
+			name: "locale sets language",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Locale("es")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `<html lang="es" xmlns=`)
+			},
+		},
+		{
+			name: "invalid language is ignored",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Language("en").Language(`en" onload="alert(1)`)
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.HTML(), `<html lang="en" xmlns=`)
+			},
+		},
+		{
+			name: "empty language removes it",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Language("en").Language("")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.HTML(), "lang=")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestBuilder_Accessibility(t *testing.T) {
+	tagRegex := regexp.MustCompile(`<(table|img)\b[^>]*>`)
+	builder := mailgen.New().
+		Language("en").
+		Product(mailgen.Product{Name: "Go-Mailgen", Logo: "https://example.com/logo.png"}).
+		Social([]mailgen.SocialLink{{Name: "GitHub", URL: "https://github.com", IconURL: "https://example.com/gh.png"}}).
+		Signature(mailgen.Signature{Name: "Jane", ImageURL: "https://example.com/jane.png"}).
+		Line("Your order has shipped.").
+		Image(mailgen.Image{Src: "https://example.com/box.png", Alt: "Package"}).
+		Table(mailgen.Table{Data: [][]mailgen.Entry{{{Key: "Item", Value: "Golang"}}}}).
+		Action("Track", "https://example.com/track")
+	for _, theme := range []string{"default", "plain"} {
+		t.Run(theme, func(t *testing.T) {
+			msg, err := builder.Theme(theme).Build()
+			require.NoError(t, err)
+			assert.Contains(t, msg.HTML(), `<html lang="en"`)
+			matches := tagRegex.FindAllStringSubmatch(msg.HTML(), -1)
+			require.NotEmpty(t, matches)
+			for _, match := range matches {
+				switch {
+				case match[1] == "img":
+					assert.Regexp(t, `\salt="[^"]*"`, match[0], "images should have an alt attribute")
+				case strings.Contains(match[0], `class="data-table-content"`):
+					assert.NotContains(t, match[0], "role=", "data tables should not be presentational")
+				default:
+					assert.Contains(t, match[0], `role="presentation"`, "layout tables should be presentational")
+				}
+			}
+		})
+	}
+}
+
+func TestBuilder_StrictAddresses(t *testing.T) {
+	t.Run("lenient by default", func(t *testing.T) {
+		msg, err := mailgen.New().To("john(at)example.com").Build()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"john(at)example.com"}, msg.To())
+	})
+
+	t.Run("valid addresses", func(t *testing.T) {
+		_, err := mailgen.New().
+			StrictAddresses(true).
+			From("no-reply@example.com", "Example").
+			ReplyTo("support@example.com").
+			To("john@example.com", "Jane Doe <jane@example.com>").
+			Cc("user@xn--mnchen-3ya.de").
+			Bcc("audit@example.com").
+			Build()
+		require.NoError(t, err)
+	})
+
+	invalid := []struct {
+		name        string
+		builderFunc func() *mailgen.Builder
+		expectedErr string
+	}{
+		{
+			name:        "To",
+			builderFunc: func() *mailgen.Builder { return mailgen.New().To("john(at)example.com") },
+			expectedErr: `invalid To address "john(at)example.com"`,
+		},
+		{
+			name:        "Cc",
+			builderFunc: func() *mailgen.Builder { return mailgen.New().Cc("jane@") },
+			expectedErr: `invalid Cc address "jane@"`,
+		},
+		{
+			name:        "Bcc",
+			builderFunc: func() *mailgen.Builder { return mailgen.New().Bcc("audit") },
+			expectedErr: `invalid Bcc address "audit"`,
+		},
+		{
+			name:        "From",
+			builderFunc: func() *mailgen.Builder { return mailgen.New().From("no-reply") },
+			expectedErr: `invalid From address "no-reply"`,
+		},
+		{
+			name:        "Reply-To",
+			builderFunc: func() *mailgen.Builder { return mailgen.New().ReplyTo("support at example.com") },
+			expectedErr: `invalid Reply-To address "support at example.com"`,
+		},
+		{
+			name:        "Sender",
+			builderFunc: func() *mailgen.Builder { return mailgen.New().Sender("notifications at example.com") },
+			expectedErr: `invalid Sender address "notifications at example.com"`,
+		},
+	}
+	for _, tc := range invalid {
+		t.Run("invalid "+tc.name, func(t *testing.T) {
+			_, err := tc.builderFunc().StrictAddresses(true).Build()
+			require.ErrorIs(t, err, mailgen.ErrInvalidAddress)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestBuilder_ComponentErrorContext(t *testing.T) {
+	htmlTmpl := htmltemplate.Must(htmltemplate.New("index.html").Parse(`
+		{{define "index.html"}}{{range .ComponentsHTML}}{{.}}{{end}}{{end}}
+		{{define "line"}}<p>{{.Text}}</p>{{end}}
+		{{define "button"}}<a href="{{.Link}}">{{.Missing}}</a>{{end}}
+		{{define "table"}}{{end}}
+	`))
+	err := mailgen.RegisterTheme("broken-button", mailgen.Theme{HTML: htmlTmpl})
+	require.NoError(t, err)
+
+	_, err = mailgen.New().
+		Theme("broken-button").
+		Line("First").
+		Line("Second").
+		Action("Go", "https://example.com").
+		Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "component[2] (*mailgen.Action): template:")
+
+	_, err = mailgen.FromSpec(mailgen.BuilderSpec{
+		Components: []mailgen.ComponentSpec{
+			{Component: &mailgen.Line{Text: "First"}},
+			{Component: failingTextComponent{}},
+		},
+	}).UsePremailer(false).Build()
+	require.ErrorIs(t, err, errPlainText)
+	assert.Contains(t, err.Error(), "component[1] (mailgen_test.failingTextComponent): plain text failed")
+}
+
+var errPlainText = errors.New("plain text failed")
+
+type failingTextComponent struct{}
+
+func (failingTextComponent) HTML(*htmltemplate.Template) (string, error) { return "<p>ok</p>", nil }
+
+func (failingTextComponent) PlainText() (string, error) { return "", errPlainText }
+
+func TestBuilder_ContextualComponent(t *testing.T) {
+	msg, err := mailgen.FromSpec(mailgen.BuilderSpec{
+		Components: []mailgen.ComponentSpec{{Component: contextComponent{}}},
+	}).TextDirection("rtl").WrapText(60).Theme("plain").Build()
+	require.NoError(t, err)
+	assert.Contains(t, msg.HTML(), ">rtl plain</p>")
+	assert.Contains(t, msg.PlainText(), "rtl 60 plain")
+
+	msg, err = mailgen.FromSpec(mailgen.BuilderSpec{
+		Components: []mailgen.ComponentSpec{{Component: contextComponent{}}},
+	}).Build()
+	require.NoError(t, err)
+	assert.Contains(t, msg.PlainText(), "ltr 0 default")
+}
+
+type contextComponent struct {
+	ctx mailgen.RenderContext
+}
+
+func (c contextComponent) WithRenderContext(ctx mailgen.RenderContext) mailgen.Component {
+	return contextComponent{ctx: ctx}
+}
+
+func (c contextComponent) HTML(*htmltemplate.Template) (string, error) {
+	return "<p>" + c.ctx.TextDirection + " " + c.ctx.Theme + "</p>", nil
+}
+
+func (c contextComponent) PlainText() (string, error) {
+	return fmt.Sprintf("%s %d %s", c.ctx.TextDirection, c.ctx.WrapColumns, c.ctx.Theme), nil
+}
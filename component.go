@@ -4,11 +4,26 @@ import (
 	"bytes"
 	"fmt"
 	htmltemplate "html/template"
+	"maps"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
+	"unicode/utf8"
+
+	"github.com/akfaiz/go-mailgen/templates"
+	"github.com/mattn/go-runewidth"
 )
 
+// dividerWidth is the number of dashes used to render a Divider in plain text.
+const dividerWidth = 40
+
+// tableTextWidth is the plain text width percentage column widths of a Table are relative to,
+// when the plain text is not wrapped.
+const tableTextWidth = 78
+
 // Component represents a part of the email message, such as a button, line, or table.
 type Component interface {
 	// HTML generates the HTML representation of the component using the provided template.
@@ -17,26 +32,201 @@ type Component interface {
 	PlainText() (string, error)
 }
 
+// RenderContext describes the email a component is rendered in.
+type RenderContext struct {
+	// TextDirection is the text direction of the email, "ltr" or "rtl".
+	TextDirection string
+	// WrapColumns is the width plain text is wrapped at, or 0 if wrapping is disabled.
+	WrapColumns int
+	// Theme is the name of the theme the email is rendered with.
+	Theme string
+	// BrandColor is the accent color of the email, used by actions and callouts without a color.
+	BrandColor string
+}
+
+// ContextualComponent is an optional interface for components whose output depends on the email
+// they are rendered in, e.g. tables laid out right-to-left in RTL emails. Before rendering the HTML
+// and plain text, the Builder replaces such components with the result of WithRenderContext.
+// Components that do not implement it are rendered as they are.
+type ContextualComponent interface {
+	Component
+	// WithRenderContext returns the component prepared for ctx. It must not modify the receiver.
+	WithRenderContext(ctx RenderContext) Component
+}
+
 var _ Component = &Table{}
 var _ Component = &Action{}
 var _ Component = &Line{}
+var _ Component = &Callout{}
+var _ Component = &Image{}
+var _ Component = &Divider{}
+var _ Component = &Coupon{}
+var _ Component = &List{}
+var _ Component = &ActionGroup{}
+var _ Component = &Shipping{}
+var _ Component = &RawHTML{}
+var _ Component = &DigestItem{}
+var _ Component = &Title{}
+var _ Component = &Section{}
+var _ Component = &Alert{}
+
+var _ ContextualComponent = &Table{}
+var _ ContextualComponent = &Action{}
+var _ ContextualComponent = &ActionGroup{}
+var _ ContextualComponent = &Callout{}
+var _ ContextualComponent = &DigestItem{}
 
 // Action represents a button or link in the email.
 type Action struct {
 	// Text is the text displayed on the button.
-	Text string
+	Text string `json:"text,omitempty"`
 	// Link is the URL the button points to.
-	Link string
-	// Color is hex color code for the button, e.g. "#3869D4".
-	Color string
+	Link string `json:"link,omitempty"`
+	// Icon is an optional icon shown before the text: either the http(s) URL of an image,
+	// rendered at 16x16 pixels, or a leading string such as an emoji, e.g. "🚀".
+	Icon string `json:"icon,omitempty"`
+	// Color is hex color code for the button, e.g. "#3869D4". Default is the brand color of the email.
+	Color string `json:"color,omitempty"`
+	// Style is the style of the button: "primary" (solid) or "secondary" (outlined). Default is "primary".
+	Style string `json:"style,omitempty"`
+	// FullWidth if true, the button spans the full width of the email instead of fitting its text.
+	FullWidth bool `json:"fullWidth,omitempty"`
 	// NoFallback if true, the action will not have a fallback text.
-	NoFallback   bool
-	FallbackText string
+	NoFallback   bool   `json:"noFallback,omitempty"`
+	FallbackText string `json:"-"`
+	// PlainTextOverride if set, is used as the plain text representation of the action
+	// instead of the default "Text (Link)".
+	PlainTextOverride string `json:"plainTextOverride,omitempty"`
+}
+
+// ActionGroup represents a group of buttons rendered side by side on a single row.
+type ActionGroup struct {
+	Actions []*Action `json:"actions,omitempty"`
 }
 
 // Line represents a simple text line in the email.
 type Line struct {
-	Text string
+	Text string `json:"text,omitempty"`
+	// Markdown if true, inline markdown in Text (**bold**, *italic*, _italic_, and [text](url) links)
+	// is rendered as HTML, and stripped in the plain text output.
+	Markdown bool `json:"markdown,omitempty"`
+	// Outro if true, the line is rendered with smaller, muted text, e.g. for closing remarks after an action.
+	Outro bool `json:"outro,omitempty"`
+}
+
+// Callout represents a highlighted box of text in the email, optionally followed by a link.
+type Callout struct {
+	// Text is the text displayed in the callout.
+	Text string `json:"text,omitempty"`
+	// Link is an optional URL displayed after the text.
+	Link string `json:"link,omitempty"`
+	// LinkText is the text of the link. Default is the Link itself.
+	LinkText string `json:"linkText,omitempty"`
+	// Color is hex color code for the callout border, e.g. "#FF6136". Default is the brand color of the email.
+	Color string `json:"color,omitempty"`
+}
+
+// Alert represents a colored, bordered box of text in the email, e.g. "Your password was changed".
+type Alert struct {
+	// Text is the text displayed in the alert.
+	Text string `json:"text,omitempty"`
+	// Level is the severity of the alert, which determines its colors:
+	// AlertInfo, AlertSuccess, AlertWarning, or AlertDanger. Default is AlertInfo.
+	Level string `json:"level,omitempty"`
+}
+
+// Image represents an inline image in the email, such as a banner or hero image.
+type Image struct {
+	// Src is the URL of the image.
+	Src string `json:"src,omitempty"`
+	// Alt is the alternative text of the image, displayed when images are not loaded.
+	Alt string `json:"alt,omitempty"`
+	// Width is the width of the image in pixels, e.g. "600".
+	Width string `json:"width,omitempty"`
+	// Height is the height of the image in pixels, e.g. "200".
+	Height string `json:"height,omitempty"`
+	// Align is the horizontal alignment of the image: "left", "center", or "right". Default is "center".
+	Align string `json:"align,omitempty"`
+	// CID is the Content-ID of an image attached to the email, e.g. "logo".
+	// If set, the image is rendered with src="cid:logo" and Src is ignored.
+	CID string `json:"cid,omitempty"`
+}
+
+// Title represents a prominent heading in the email, e.g. "Your receipt".
+type Title struct {
+	Text string `json:"text,omitempty"`
+}
+
+// Section represents a sub-heading between blocks of content in the email, e.g. "Usage this month".
+type Section struct {
+	Text string `json:"text,omitempty"`
+}
+
+// Divider represents a horizontal rule separating sections of the email.
+type Divider struct{}
+
+// Coupon represents a promo code displayed in a dashed box in the email.
+type Coupon struct {
+	// Code is the promo code, e.g. "SAVE10".
+	Code string `json:"code,omitempty"`
+	// Description is an optional line displayed below the code.
+	Description string `json:"description,omitempty"`
+}
+
+// CouponConfig configures the coupon added via Builder.Coupon.
+type CouponConfig struct {
+	// Description is an optional line displayed below the code, e.g. "10% off your next order".
+	Description string
+}
+
+// Shipping represents a highlighted block with the estimated delivery date and tracking details of a shipment.
+type Shipping struct {
+	// Carrier is the name of the shipping carrier, e.g. "UPS".
+	Carrier string `json:"carrier,omitempty"`
+	// TrackingNumber is the tracking number of the shipment.
+	TrackingNumber string `json:"trackingNumber,omitempty"`
+	// TrackingURL is an optional URL the tracking number links to.
+	TrackingURL string `json:"trackingURL,omitempty"`
+	// EstimatedDelivery is the formatted estimated delivery date, e.g. "Tuesday, Jan 9".
+	EstimatedDelivery string `json:"estimatedDelivery,omitempty"`
+}
+
+// ShippingConfig configures the shipping block added via Builder.Shipping.
+type ShippingConfig struct {
+	// Carrier is the name of the shipping carrier, e.g. "UPS".
+	Carrier string
+	// TrackingNumber is the tracking number of the shipment.
+	TrackingNumber string
+	// TrackingURL is an optional URL the tracking number links to.
+	TrackingURL string
+	// EstimatedDelivery is the estimated delivery date. If zero, no delivery date is displayed.
+	EstimatedDelivery time.Time
+	// DateFormat is the layout used to format EstimatedDelivery. Default is "Monday, Jan 2".
+	DateFormat string
+}
+
+// RawHTML represents custom HTML inserted into the email as is, without escaping.
+// It has no plain text representation; use Builder.AutoPlainText to derive the plain text from the HTML.
+type RawHTML struct {
+	// Content is the HTML content, e.g. "<p>Hello <b>world</b></p>".
+	Content string `json:"content,omitempty"`
+}
+
+// DigestItem represents a titled block of components, rendered as a separate card.
+// Multiple digest items stack to form a digest email, e.g. a daily summary.
+type DigestItem struct {
+	// Title is the heading of the item.
+	Title string `json:"title,omitempty"`
+	// Components contains the lines, actions, tables, etc. of the item.
+	Components []Component `json:"components,omitempty"`
+}
+
+// List represents a bulleted or numbered list in the email.
+type List struct {
+	// Items contains the list items. Multi-line items are indented in plain text.
+	Items []string `json:"items,omitempty"`
+	// Ordered if true, the list is numbered; otherwise it is bulleted.
+	Ordered bool `json:"ordered,omitempty"`
 }
 
 // Table represents a structured table in the email.
@@ -63,25 +253,67 @@ type Line struct {
 //	    },
 //	}
 type Table struct {
+	// Caption optionally sets a title describing the table, e.g. "Order #12345 details", rendered as its
+	// caption in HTML, which is announced by screen readers, and as a line above the plain text table.
+	Caption string `json:"caption,omitempty"`
 	// Data contains the rows of the table, each row is a slice of Entry.
 	// Each Entry has a Key and Value, where Key is the column name.
-	Data [][]Entry
+	Data [][]Entry `json:"data,omitempty"`
+	// Headers optionally sets the column labels by column order, e.g. "Unit Price" for a "price" column.
+	// Columns without a header are labeled with their capitalized Key.
+	Headers []string `json:"headers,omitempty"`
+	// Footer is an optional summary row, e.g. the total of an invoice, rendered below the data rows
+	// and visually separated from them. Its entries are matched to the columns by Key.
+	Footer []Entry `json:"footer,omitempty"`
+	// SumColumns optionally lists the keys of numeric columns whose totals are computed and set in the
+	// Footer, replacing its entries with the same keys, e.g. the "price" column of an invoice.
+	// Empty cells are ignored, as are the other values that are not numbers unless StrictSum is set.
+	SumColumns []string `json:"sumColumns,omitempty"`
+	// StrictSum if true, rendering fails with an error wrapping ErrInvalidTableValue when a SumColumns
+	// column has a value that is not a number.
+	StrictSum bool `json:"strictSum,omitempty"`
 	// Columns defines column properties like width and alignment.
-	Columns Columns
+	Columns Columns `json:"columns"`
+	// Striped if true, alternating data rows are highlighted with the stripe color of the theme.
+	// It has no effect on the plain text output.
+	Striped bool `json:"striped,omitempty"`
+	// PlainTextOverride if set, is used as the plain text representation of the table
+	// instead of the generated text table.
+	PlainTextOverride string `json:"plainTextOverride,omitempty"`
+
+	// rtl is set via WithRenderContext when the text direction of the email is right-to-left.
+	rtl bool
+	// wrapColumns is set via WithRenderContext to the width the plain text is wrapped at.
+	wrapColumns int
 }
 
 // Entry represents a single entry in the table with a key and value.
 type Entry struct {
-	Key   string
-	Value string
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value,omitempty"`
+	// Span optionally sets the number of columns the entry spans, starting at the column of its Key,
+	// or at the first column if no column has its Key. E.g. a full-width note row of a receipt is a row
+	// with a single entry spanning all columns. The entries of the first data row, which defines the columns,
+	// should not span.
+	Span int `json:"span,omitempty"`
 }
 
 // Columns defines the structure of the table columns.
 type Columns struct {
 	// CustomWidth allows setting specific widths for columns.
-	CustomWidth map[string]string
+	CustomWidth map[string]string `json:"customWidth,omitempty"`
 	// CustomAlign allows setting specific alignments for columns.
-	CustomAlign map[string]string
+	CustomAlign map[string]string `json:"customAlign,omitempty"`
+	// HeaderAlign allows setting specific alignments for the header labels of columns.
+	// By default, a header label has the alignment of its column.
+	HeaderAlign map[string]string `json:"headerAlign,omitempty"`
+	// MaxCellWidth allows setting the maximum number of characters shown in the cells of specific columns.
+	// Longer values are truncated with an ellipsis. Default is no truncation.
+	MaxCellWidth map[string]int `json:"maxCellWidth,omitempty"`
+	// Currency allows formatting the numeric values of specific columns as amounts in a currency,
+	// given by its ISO 4217 code, e.g. "1099" in a "USD" column is shown as "$1,099.00".
+	// Currency columns are right-aligned unless set otherwise in CustomAlign.
+	Currency map[string]string `json:"currency,omitempty"`
 }
 
 func (a Action) HTML(tmpl *htmltemplate.Template) (string, error) {
@@ -93,13 +325,66 @@ func (a Action) HTML(tmpl *htmltemplate.Template) (string, error) {
 	return buf.String(), nil
 }
 
+func (a Action) WithRenderContext(ctx RenderContext) Component {
+	if a.Color == "" {
+		a.Color = ctx.BrandColor
+	}
+	return &a
+}
+
 func (a Action) PlainText() (string, error) {
+	if a.PlainTextOverride != "" {
+		return a.PlainTextOverride, nil
+	}
+	if a.Icon != "" && !templates.IsURL(a.Icon) {
+		return a.Icon + " " + a.Text + " (" + a.Link + ")", nil
+	}
 	return a.Text + " (" + a.Link + ")", nil
 }
 
+func (g ActionGroup) HTML(tmpl *htmltemplate.Template) (string, error) {
+	var buf bytes.Buffer
+	err := tmpl.ExecuteTemplate(&buf, "button_group", g)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (g ActionGroup) WithRenderContext(ctx RenderContext) Component {
+	actions := make([]*Action, len(g.Actions))
+	for i, action := range g.Actions {
+		actions[i] = action.WithRenderContext(ctx).(*Action) //nolint:forcetypeassert // always an *Action
+	}
+	g.Actions = actions
+	return &g
+}
+
+func (g ActionGroup) PlainText() (string, error) {
+	lines := make([]string, 0, len(g.Actions))
+	for _, action := range g.Actions {
+		text, err := action.PlainText()
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, text)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 func (l Line) HTML(tmpl *htmltemplate.Template) (string, error) {
+	var data any = l
+	if l.Markdown {
+		data = struct {
+			Text  htmltemplate.HTML
+			Outro bool
+		}{
+			Text:  htmltemplate.HTML(markdownToHTML(l.Text)), //nolint:gosec // markdown is escaped before conversion
+			Outro: l.Outro,
+		}
+	}
 	var buf bytes.Buffer
-	err := tmpl.ExecuteTemplate(&buf, "line", l)
+	err := tmpl.ExecuteTemplate(&buf, "line", data)
 	if err != nil {
 		return "", err
 	}
@@ -107,44 +392,352 @@ func (l Line) HTML(tmpl *htmltemplate.Template) (string, error) {
 }
 
 func (l Line) PlainText() (string, error) {
+	if l.Markdown {
+		return markdownToText(l.Text), nil
+	}
 	return l.Text, nil
 }
 
+func (c Callout) HTML(tmpl *htmltemplate.Template) (string, error) {
+	var buf bytes.Buffer
+	err := tmpl.ExecuteTemplate(&buf, "callout", c)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (c Callout) WithRenderContext(ctx RenderContext) Component {
+	if c.Color == "" {
+		c.Color = ctx.BrandColor
+	}
+	return &c
+}
+
+func (c Callout) PlainText() (string, error) {
+	if c.Link == "" {
+		return c.Text, nil
+	}
+	if c.LinkText == "" || c.LinkText == c.Link {
+		return c.Text + "\n" + c.Link, nil
+	}
+	return c.Text + "\n" + c.LinkText + ": " + c.Link, nil
+}
+
+// alertColors maps each alert level to its border and background colors.
+var alertColors = map[string]struct{ Color, Background string }{
+	AlertInfo:    {Color: "#3869D4", Background: "#EEF3FC"},
+	AlertSuccess: {Color: "#22BC66", Background: "#E9F8F0"},
+	AlertWarning: {Color: "#F5A623", Background: "#FEF6E7"},
+	AlertDanger:  {Color: "#FF6136", Background: "#FFEFEB"},
+}
+
+// level returns the level of the alert, falling back to AlertInfo for unknown levels.
+func (a Alert) level() string {
+	if _, ok := alertColors[a.Level]; ok {
+		return a.Level
+	}
+	return AlertInfo
+}
+
+func (a Alert) HTML(tmpl *htmltemplate.Template) (string, error) {
+	colors := alertColors[a.level()]
+	data := struct {
+		Text       string
+		Color      string
+		Background string
+	}{
+		Text:       a.Text,
+		Color:      colors.Color,
+		Background: colors.Background,
+	}
+	var buf bytes.Buffer
+	err := tmpl.ExecuteTemplate(&buf, "alert", data)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (a Alert) PlainText() (string, error) {
+	return "[" + strings.ToUpper(a.level()) + "] " + a.Text, nil
+}
+
+func (i Image) HTML(tmpl *htmltemplate.Template) (string, error) {
+	var data any = i
+	if i.CID != "" {
+		data = struct {
+			Image
+			Src htmltemplate.URL
+		}{
+			Image: i,
+			Src:   htmltemplate.URL("cid:" + i.CID), //nolint:gosec // the cid scheme is not allowlisted by html/template
+		}
+	}
+	var buf bytes.Buffer
+	err := tmpl.ExecuteTemplate(&buf, "image", data)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (i Image) PlainText() (string, error) {
+	if i.Alt != "" || i.CID != "" {
+		return i.Alt, nil
+	}
+	return i.Src, nil
+}
+
+func (t Title) HTML(tmpl *htmltemplate.Template) (string, error) {
+	var buf bytes.Buffer
+	err := tmpl.ExecuteTemplate(&buf, "title", t)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (t Title) PlainText() (string, error) {
+	return strings.ToUpper(t.Text), nil
+}
+
+func (s Section) HTML(tmpl *htmltemplate.Template) (string, error) {
+	var buf bytes.Buffer
+	err := tmpl.ExecuteTemplate(&buf, "section", s)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (s Section) PlainText() (string, error) {
+	return s.Text + "\n" + strings.Repeat("=", utf8.RuneCountInString(s.Text)), nil
+}
+
+func (d Divider) HTML(tmpl *htmltemplate.Template) (string, error) {
+	var buf bytes.Buffer
+	err := tmpl.ExecuteTemplate(&buf, "divider", d)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (d Divider) PlainText() (string, error) {
+	return strings.Repeat("-", dividerWidth), nil
+}
+
+func (c Coupon) HTML(tmpl *htmltemplate.Template) (string, error) {
+	var buf bytes.Buffer
+	err := tmpl.ExecuteTemplate(&buf, "coupon", c)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (c Coupon) PlainText() (string, error) {
+	text := templates.BoxString("[ " + c.Code + " ]")
+	if c.Description != "" {
+		text += "\n" + c.Description
+	}
+	return text, nil
+}
+
+func (s Shipping) HTML(tmpl *htmltemplate.Template) (string, error) {
+	var buf bytes.Buffer
+	err := tmpl.ExecuteTemplate(&buf, "shipping", s)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (s Shipping) PlainText() (string, error) {
+	var lines []string
+	if s.EstimatedDelivery != "" {
+		lines = append(lines, "Arrives "+s.EstimatedDelivery)
+	}
+	if s.Carrier != "" {
+		lines = append(lines, "Carrier: "+s.Carrier)
+	}
+	switch {
+	case s.TrackingNumber != "" && s.TrackingURL != "":
+		lines = append(lines, "Tracking number: "+s.TrackingNumber+" ("+s.TrackingURL+")")
+	case s.TrackingNumber != "":
+		lines = append(lines, "Tracking number: "+s.TrackingNumber)
+	case s.TrackingURL != "":
+		lines = append(lines, "Track your package: "+s.TrackingURL)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (r RawHTML) HTML(_ *htmltemplate.Template) (string, error) {
+	return r.Content, nil
+}
+
+func (r RawHTML) PlainText() (string, error) {
+	return "", nil
+}
+
+func (d DigestItem) HTML(tmpl *htmltemplate.Template) (string, error) {
+	content := make([]htmltemplate.HTML, 0, len(d.Components))
+	for i, comp := range d.Components {
+		html, err := comp.HTML(tmpl)
+		if err != nil {
+			return "", componentError(i, comp, err)
+		}
+		content = append(content, htmltemplate.HTML(html)) //nolint:gosec // trusted HTML from templates
+	}
+	data := struct {
+		Title   string
+		Content []htmltemplate.HTML
+	}{
+		Title:   d.Title,
+		Content: content,
+	}
+	var buf bytes.Buffer
+	err := tmpl.ExecuteTemplate(&buf, "digest_item", data)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (d DigestItem) WithRenderContext(ctx RenderContext) Component {
+	components := make([]Component, len(d.Components))
+	for i, comp := range d.Components {
+		if c, ok := comp.(ContextualComponent); ok {
+			comp = c.WithRenderContext(ctx)
+		}
+		components[i] = comp
+	}
+	d.Components = components
+	return &d
+}
+
+func (d DigestItem) PlainText() (string, error) {
+	var parts []string
+	if d.Title != "" {
+		parts = append(parts, d.Title+"\n"+strings.Repeat("=", utf8.RuneCountInString(d.Title)))
+	}
+	for i, comp := range d.Components {
+		text, err := comp.PlainText()
+		if err != nil {
+			return "", componentError(i, comp, err)
+		}
+		if text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+func (l List) HTML(tmpl *htmltemplate.Template) (string, error) {
+	var buf bytes.Buffer
+	err := tmpl.ExecuteTemplate(&buf, "list", l)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (l List) PlainText() (string, error) {
+	lines := make([]string, 0, len(l.Items))
+	for i, item := range l.Items {
+		marker := "- "
+		if l.Ordered {
+			marker = strconv.Itoa(i+1) + ". "
+		}
+		indent := strings.Repeat(" ", len(marker))
+		lines = append(lines, marker+strings.ReplaceAll(item, "\n", "\n"+indent))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 func (t Table) HTML(tmpl *htmltemplate.Template) (string, error) {
+	t, err := t.withTotals()
+	if err != nil {
+		return "", err
+	}
+	t = t.withCurrency()
+	data := struct {
+		Table
+		RTL          bool
+		DefaultAlign string
+		HeaderLabels []string
+		FooterCells  []Entry
+	}{
+		Table:        t,
+		RTL:          t.rtl,
+		DefaultAlign: t.defaultAlign(),
+		HeaderLabels: t.headerLabels(),
+		FooterCells:  t.footerCells(),
+	}
 	var buf bytes.Buffer
-	err := tmpl.ExecuteTemplate(&buf, "table", t)
+	err = tmpl.ExecuteTemplate(&buf, "table", data)
 	if err != nil {
 		return "", err
 	}
 	return buf.String(), nil
 }
 
+func (t Table) WithRenderContext(ctx RenderContext) Component {
+	t.rtl = ctx.TextDirection == "rtl"
+	t.wrapColumns = ctx.WrapColumns
+	return &t
+}
+
 func (t Table) PlainText() (string, error) {
+	if t.PlainTextOverride != "" {
+		return t.PlainTextOverride, nil
+	}
 	if len(t.Data) == 0 || len(t.Data[0]) == 0 {
 		return "", nil
 	}
+	t, err := t.withTotals()
+	if err != nil {
+		return "", err
+	}
+	t = t.withCurrency()
+	data := t.truncateRows(t.Data)
+	var footer [][]Entry
+	if len(t.Footer) > 0 {
+		footer = t.truncateRows([][]Entry{t.Footer})
+	}
 
 	// Extract column order from first row
 	columnNames := make([]string, 0, len(t.Data[0]))
-	for _, entry := range t.Data[0] {
+	labels := make(map[string]string, len(t.Data[0]))
+	for i, entry := range t.Data[0] {
 		columnNames = append(columnNames, entry.Key)
+		labels[entry.Key] = t.headerLabel(i, entry.Key)
+	}
+	if t.rtl {
+		// Right-to-left tables are laid out from the right, like in the HTML output
+		slices.Reverse(columnNames)
 	}
 
 	// Calculate column widths
 	colWidths := make(map[string]int)
 	for _, col := range columnNames {
-		colWidths[col] = len(col)
+		colWidths[col] = runewidth.StringWidth(labels[col])
 		if wStr, ok := t.Columns.CustomWidth[col]; ok {
-			if w, err := strconv.Atoi(wStr); err == nil {
+			if w, ok := t.textWidth(wStr, len(columnNames)); ok {
 				colWidths[col] = w
 			}
 		}
 	}
 
 	// If no custom width, compute max width from data
-	for _, row := range t.Data {
+	for _, row := range slices.Concat(data, footer) {
 		for _, entry := range row {
-			width := len(entry.Value)
+			if entry.Span > 1 {
+				// Spanning entries get the combined width of their columns
+				continue
+			}
+			width := runewidth.StringWidth(entry.Value)
 			if width > colWidths[entry.Key] {
 				colWidths[entry.Key] = width
 			}
@@ -153,23 +746,33 @@ func (t Table) PlainText() (string, error) {
 
 	var sb strings.Builder
 
-	t.writeHeader(&sb, columnNames, colWidths)
-	t.writeData(&sb, t.Data, columnNames, colWidths)
+	if t.Caption != "" {
+		sb.WriteString(t.Caption + "\n\n")
+	}
+	t.writeHeader(&sb, columnNames, labels, colWidths)
+	t.writeData(&sb, data, columnNames, colWidths)
+	if len(footer) > 0 {
+		t.writeSeparator(&sb, columnNames, colWidths)
+		t.writeData(&sb, footer, columnNames, colWidths)
+	}
 
 	return sb.String(), nil
 }
 
-func (t Table) writeHeader(sb *strings.Builder, columnNames []string, colWidths map[string]int) {
+func (t Table) writeHeader(sb *strings.Builder, columnNames []string, labels map[string]string, colWidths map[string]int) {
 	// Header row
 	for i, col := range columnNames {
-		sb.WriteString(t.padString(t.capitalize(col), colWidths[col], t.Columns.CustomAlign[col]))
+		sb.WriteString(t.padString(labels[col], colWidths[col], t.headerAlign(col)))
 		if i < len(columnNames)-1 {
 			sb.WriteString(" | ")
 		}
 	}
 	sb.WriteString("\n")
 
-	// Separator row
+	t.writeSeparator(sb, columnNames, colWidths)
+}
+
+func (t Table) writeSeparator(sb *strings.Builder, columnNames []string, colWidths map[string]int) {
 	for i, col := range columnNames {
 		sb.WriteString(strings.Repeat("-", colWidths[col]))
 		if i < len(columnNames)-1 {
@@ -185,9 +788,19 @@ func (t Table) writeData(sb *strings.Builder, data [][]Entry, columnNames []stri
 		for _, e := range row {
 			entryMap[e.Key] = e.Value
 		}
-		for i, col := range columnNames {
-			val := entryMap[col]
-			sb.WriteString(t.padString(val, colWidths[col], t.Columns.CustomAlign[col]))
+		spans := t.spans(row)
+		for i := 0; i < len(columnNames); i++ {
+			col := columnNames[i]
+			val, width, align := entryMap[col], colWidths[col], t.align(col)
+			if index, ok := spans[col]; ok {
+				// The columns spanned by an entry are adjacent, so they are merged into a single cell
+				for i+1 < len(columnNames) && t.spanned(spans, columnNames[i+1], index) {
+					i++
+					width += colWidths[columnNames[i]] + len(" | ")
+				}
+				val, align = row[index].Value, t.align(row[index].Key)
+			}
+			sb.WriteString(t.padString(val, width, align))
 			if i < len(columnNames)-1 {
 				sb.WriteString(" | ")
 			}
@@ -196,17 +809,261 @@ func (t Table) writeData(sb *strings.Builder, data [][]Entry, columnNames []stri
 	}
 }
 
+// spans returns the index in the row of the entries spanning several columns,
+// by the keys of the columns they span.
+func (t Table) spans(row []Entry) map[string]int {
+	if len(t.Data) == 0 {
+		return nil
+	}
+	var spans map[string]int
+	for i, entry := range row {
+		if entry.Span <= 1 {
+			continue
+		}
+		start := max(slices.IndexFunc(t.Data[0], func(e Entry) bool { return e.Key == entry.Key }), 0)
+		end := min(start+entry.Span, len(t.Data[0]))
+		if spans == nil {
+			spans = make(map[string]int)
+		}
+		for _, column := range t.Data[0][start:end] {
+			spans[column.Key] = i
+		}
+	}
+	return spans
+}
+
+// spanned reports whether the column with the given key is spanned by the entry at index.
+func (t Table) spanned(spans map[string]int, key string, index int) bool {
+	i, ok := spans[key]
+	return ok && i == index
+}
+
+// textWidth returns the plain text width of a column with the given custom width, which is either
+// a number of characters or a percentage, e.g. "20%", of the plain text width left by the column separators.
+func (t Table) textWidth(width string, columns int) (int, bool) {
+	percent, ok := strings.CutSuffix(strings.TrimSpace(width), "%")
+	if !ok {
+		w, err := strconv.Atoi(width)
+		return w, err == nil
+	}
+	p, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+	if err != nil || p <= 0 {
+		return 0, false
+	}
+	total := tableTextWidth
+	if t.wrapColumns > 0 {
+		total = t.wrapColumns
+	}
+	available := max(total-len(" | ")*(columns-1), columns)
+	return max(int(float64(available)*min(p, 100)/100), 1), true //nolint:mnd // percentage
+}
+
+// defaultAlign returns the alignment of the columns without a custom alignment.
+func (t Table) defaultAlign() string {
+	if t.rtl {
+		return "right"
+	}
+	return "left"
+}
+
+// align returns the alignment of the column with the given key.
+func (t Table) align(key string) string {
+	if align := t.Columns.CustomAlign[key]; align != "" {
+		return align
+	}
+	return t.defaultAlign()
+}
+
+// headerAlign returns the alignment of the header label of the column with the given key.
+func (t Table) headerAlign(key string) string {
+	if align := t.Columns.HeaderAlign[key]; align != "" {
+		return align
+	}
+	return t.align(key)
+}
+
+// headerLabels returns the labels of the columns of the first data row.
+func (t Table) headerLabels() []string {
+	if len(t.Data) == 0 {
+		return nil
+	}
+	labels := make([]string, 0, len(t.Data[0]))
+	for i, entry := range t.Data[0] {
+		labels = append(labels, t.headerLabel(i, entry.Key))
+	}
+	return labels
+}
+
+// headerLabel returns the label of the column at index i, which defaults to its capitalized key.
+func (t Table) headerLabel(i int, key string) string {
+	if i < len(t.Headers) && t.Headers[i] != "" {
+		return t.Headers[i]
+	}
+	return t.capitalize(key)
+}
+
+// footerCells returns the entries of the footer in the column order of the first data row,
+// with an empty value for the columns missing from the footer and a single cell for the columns
+// spanned by an entry.
+func (t Table) footerCells() []Entry {
+	if len(t.Footer) == 0 || len(t.Data) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(t.Footer))
+	for _, entry := range t.Footer {
+		values[entry.Key] = entry.Value
+	}
+	spans := t.spans(t.Footer)
+	cells := make([]Entry, 0, len(t.Data[0]))
+	for i := 0; i < len(t.Data[0]); i++ {
+		key := t.Data[0][i].Key
+		index, ok := spans[key]
+		if !ok {
+			cells = append(cells, Entry{Key: key, Value: values[key]})
+			continue
+		}
+		span := t.Footer[index]
+		span.Span = 1
+		for i+1 < len(t.Data[0]) && t.spanned(spans, t.Data[0][i+1].Key, index) {
+			i++
+			span.Span++
+		}
+		cells = append(cells, span)
+	}
+	return cells
+}
+
+// truncateRows returns the rows with the values longer than Columns.MaxCellWidth truncated.
+func (t Table) truncateRows(rows [][]Entry) [][]Entry {
+	if len(t.Columns.MaxCellWidth) == 0 {
+		return rows
+	}
+	data := make([][]Entry, len(rows))
+	for i, row := range rows {
+		data[i] = make([]Entry, len(row))
+		for j, entry := range row {
+			entry.Value = truncateText(entry.Value, t.Columns.MaxCellWidth[entry.Key])
+			data[i][j] = entry
+		}
+	}
+	return data
+}
+
+// withTotals returns a copy of the table with the totals of the Table.SumColumns set in the footer.
+func (t Table) withTotals() (Table, error) {
+	if len(t.SumColumns) == 0 {
+		return t, nil
+	}
+	footer := slices.Clone(t.Footer)
+	for _, key := range t.SumColumns {
+		total, err := t.sum(key)
+		if err != nil {
+			return t, err
+		}
+		if i := slices.IndexFunc(footer, func(e Entry) bool { return e.Key == key }); i >= 0 {
+			footer[i].Value = total
+		} else {
+			footer = append(footer, Entry{Key: key, Value: total})
+		}
+	}
+	t.Footer = footer
+	return t, nil
+}
+
+// sum returns the total of the numeric values of the column with the given key,
+// written with as many decimals as the most precise value.
+func (t Table) sum(key string) (string, error) {
+	var total float64
+	decimals := 0
+	for _, row := range t.Data {
+		for _, entry := range row {
+			value := strings.TrimSpace(entry.Value)
+			if entry.Key != key || value == "" {
+				continue
+			}
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+				if t.StrictSum {
+					return "", fmt.Errorf("%w: %q in column %q is not a number", ErrInvalidTableValue, entry.Value, key)
+				}
+				continue
+			}
+			total += n
+			if _, fraction, ok := strings.Cut(value, "."); ok {
+				decimals = max(decimals, len(fraction))
+			}
+		}
+	}
+	return strconv.FormatFloat(total, 'f', decimals, 64), nil
+}
+
+// withCurrency returns a copy of the table with the values of the Columns.Currency columns formatted
+// as amounts, and these columns right-aligned unless they have a custom alignment.
+func (t Table) withCurrency() Table {
+	if len(t.Columns.Currency) == 0 {
+		return t
+	}
+	format := func(entry Entry) Entry {
+		if code, ok := t.Columns.Currency[entry.Key]; ok {
+			entry.Value = formatCurrency(entry.Value, code)
+		}
+		return entry
+	}
+	data := make([][]Entry, len(t.Data))
+	for i, row := range t.Data {
+		data[i] = make([]Entry, len(row))
+		for j, entry := range row {
+			data[i][j] = format(entry)
+		}
+	}
+	t.Data = data
+	if len(t.Footer) > 0 {
+		footer := make([]Entry, len(t.Footer))
+		for i, entry := range t.Footer {
+			footer[i] = format(entry)
+		}
+		t.Footer = footer
+	}
+	align := maps.Clone(t.Columns.CustomAlign)
+	if align == nil {
+		align = make(map[string]string, len(t.Columns.Currency))
+	}
+	for key := range t.Columns.Currency {
+		if align[key] == "" {
+			align[key] = "right"
+		}
+	}
+	t.Columns.CustomAlign = align
+	return t
+}
+
+// truncateText shortens s to at most limit characters, ending it with "..." when it is truncated.
+// A limit of zero or less means no truncation.
+func truncateText(s string, limit int) string {
+	const ellipsis = "..."
+	runes := []rune(s)
+	if limit <= 0 || len(runes) <= limit {
+		return s
+	}
+	if limit <= len(ellipsis) {
+		return string(runes[:limit])
+	}
+	return string(runes[:limit-len(ellipsis)]) + ellipsis
+}
+
+// padString pads s with spaces to the given display width, in which East Asian wide characters
+// count as two columns and combining marks as none, so that the columns of the table line up.
 func (t Table) padString(s string, width int, align string) string {
+	pad := max(width-runewidth.StringWidth(s), 0)
 	switch align {
 	case "right":
-		return fmt.Sprintf("%*s", width, s)
+		return strings.Repeat(" ", pad) + s
 	case "center":
-		pad := width - len(s)
 		left := pad / 2 //nolint:mnd // integer division
 		right := pad - left
 		return strings.Repeat(" ", left) + s + strings.Repeat(" ", right)
 	default: // left
-		return fmt.Sprintf("%-*s", width, s)
+		return s + strings.Repeat(" ", pad)
 	}
 }
 
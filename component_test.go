@@ -2,6 +2,7 @@ package mailgen_test
 
 import (
 	htmltemplate "html/template"
+	"strings"
 	"testing"
 
 	"github.com/akfaiz/go-mailgen"
@@ -94,6 +95,28 @@ func TestLine_HTML(t *testing.T) {
 			expected: `<p></p>`,
 			wantErr:  false,
 		},
+		{
+			name: "line with markdown",
+			line: mailgen.Line{
+				Text:     "**Bold**, *italic*, _also italic_ and [a link](https://example.com/a_b?x=1&y=2)",
+				Markdown: true,
+			},
+			template: `{{define "line"}}<p>{{.Text}}</p>{{end}}`,
+			expected: `<p><strong>Bold</strong>, <em>italic</em>, <em>also italic</em> and ` +
+				`<a href="https://example.com/a_b?x=1&amp;y=2" target="_blank">a link</a></p>`,
+			wantErr: false,
+		},
+		{
+			name: "line with markdown escapes html",
+			line: mailgen.Line{
+				Text:     `<script>alert(1)</script> **<b>bold</b>** [x](javascript:void) snake_case_word`,
+				Markdown: true,
+			},
+			template: `{{define "line"}}<p>{{.Text}}</p>{{end}}`,
+			expected: `<p>&lt;script&gt;alert(1)&lt;/script&gt; <strong>&lt;b&gt;bold&lt;/b&gt;</strong> x` +
+				` snake_case_word</p>`,
+			wantErr: false,
+		},
 		{
 			name: "template execution error",
 			line: mailgen.Line{
@@ -193,6 +216,24 @@ func TestTable_HTML(t *testing.T) {
 			expected: `<table><tr><td>Test: Data</td></tr></table>`,
 			wantErr:  false,
 		},
+		{
+			name: "table with currency column",
+			table: mailgen.Table{
+				Data: [][]mailgen.Entry{
+					{
+						{Key: "item", Value: "Golang"},
+						{Key: "price", Value: "1099"},
+					},
+				},
+				Footer: []mailgen.Entry{{Key: "price", Value: "1099"}},
+				Columns: mailgen.Columns{
+					Currency: map[string]string{"price": "USD"},
+				},
+			},
+			template: `{{define "table"}}<table>{{range .Data}}<tr>{{range .}}<td style="text-align:{{or (index $.Columns.CustomAlign .Key) $.DefaultAlign}}">{{.Value}}</td>{{end}}</tr>{{end}}{{range .FooterCells}}<td>{{.Value}}</td>{{end}}</table>{{end}}`,
+			expected: `<table><tr><td style="text-align:left">Golang</td><td style="text-align:right">$1,099.00</td></tr><td></td><td>$1,099.00</td></table>`,
+			wantErr:  false,
+		},
 		{
 			name: "template execution error",
 			table: mailgen.Table{
@@ -248,6 +289,17 @@ func TestTable_PlainText(t *testing.T) {
 			expected: "Name | Age\n-----+----\nJohn | 30 \nJane | 25 \n",
 			wantErr:  false,
 		},
+		{
+			name: "table with plain text override",
+			table: mailgen.Table{
+				Data: [][]mailgen.Entry{
+					{{Key: "name", Value: "John"}},
+				},
+				PlainTextOverride: "Name: John",
+			},
+			expected: "Name: John",
+			wantErr:  false,
+		},
 		{
 			name: "empty table data",
 			table: mailgen.Table{
@@ -289,6 +341,54 @@ func TestTable_PlainText(t *testing.T) {
 			expected: "Id    | Name      \n------+-----------\n1     | John      \n2     | Jane      \n",
 			wantErr:  false,
 		},
+		{
+			name: "table with percentage width",
+			table: mailgen.Table{
+				Data: [][]mailgen.Entry{
+					{
+						{Key: "id", Value: "1"},
+						{Key: "name", Value: "John"},
+						{Key: "note", Value: "Longer than its column width"},
+					},
+				},
+				Columns: mailgen.Columns{
+					CustomWidth: map[string]string{
+						"id":   "10%",
+						"name": "25%",
+						"note": "5%",
+					},
+				},
+			},
+			expected: "Id      | Name               | Note                        \n" +
+				"--------+--------------------+-----------------------------\n" +
+				"1       | John               | Longer than its column width\n",
+			wantErr: false,
+		},
+		{
+			name: "table with wide characters",
+			table: mailgen.Table{
+				Data: [][]mailgen.Entry{
+					{
+						{Key: "name", Value: "José"},
+						{Key: "item", Value: "日本茶"},
+						{Key: "qty", Value: "1"},
+					},
+					{
+						{Key: "name", Value: "Zoë"},
+						{Key: "item", Value: "Tea"},
+						{Key: "qty", Value: "12"},
+					},
+				},
+				Columns: mailgen.Columns{
+					CustomAlign: map[string]string{"item": "center", "qty": "right"},
+				},
+			},
+			expected: "Name |  Item  | Qty\n" +
+				"-----+--------+----\n" +
+				"José | 日本茶 |   1\n" +
+				"Zoë  |  Tea   |  12\n",
+			wantErr: false,
+		},
 		{
 			name: "table with custom alignment",
 			table: mailgen.Table{
@@ -312,6 +412,199 @@ func TestTable_PlainText(t *testing.T) {
 			expected: "Name | Score\n-----+------\nJohn |    95\nJane |    87\n",
 			wantErr:  false,
 		},
+		{
+			name: "table with caption",
+			table: mailgen.Table{
+				Caption: "Order #12345 details",
+				Data: [][]mailgen.Entry{
+					{{Key: "item", Value: "Golang"}},
+				},
+			},
+			expected: "Order #12345 details\n\nItem  \n------\nGolang\n",
+			wantErr:  false,
+		},
+		{
+			name: "table with header alignment",
+			table: mailgen.Table{
+				Data: [][]mailgen.Entry{
+					{
+						{Key: "status", Value: "delivered"},
+						{Key: "amount", Value: "1234"},
+						{Key: "note", Value: "left as is"},
+					},
+				},
+				Columns: mailgen.Columns{
+					CustomAlign: map[string]string{"status": "center", "amount": "right"},
+					HeaderAlign: map[string]string{"status": "left", "note": "right"},
+				},
+			},
+			expected: "Status    | Amount |       Note\n" +
+				"----------+--------+-----------\n" +
+				"delivered |   1234 | left as is\n",
+			wantErr: false,
+		},
+		{
+			name: "table with headers",
+			table: mailgen.Table{
+				Data: [][]mailgen.Entry{
+					{
+						{Key: "item", Value: "Golang"},
+						{Key: "price", Value: "$10.99"},
+					},
+				},
+				Headers: []string{"", "Unit Price"},
+			},
+			expected: "Item   | Unit Price\n-------+-----------\nGolang | $10.99    \n",
+			wantErr:  false,
+		},
+		{
+			name: "table with footer",
+			table: mailgen.Table{
+				Data: [][]mailgen.Entry{
+					{
+						{Key: "item", Value: "Golang"},
+						{Key: "price", Value: "$10.99"},
+					},
+					{
+						{Key: "item", Value: "Mailgen"},
+						{Key: "price", Value: "$1.99"},
+					},
+				},
+				Footer: []mailgen.Entry{
+					{Key: "price", Value: "$12.98"},
+					{Key: "item", Value: "Total"},
+				},
+				Columns: mailgen.Columns{
+					CustomAlign: map[string]string{"price": "right"},
+				},
+			},
+			expected: "Item    |  Price\n--------+-------\nGolang  | $10.99\nMailgen |  $1.99\n--------+-------\nTotal   | $12.98\n",
+			wantErr:  false,
+		},
+		{
+			name: "table with max cell width",
+			table: mailgen.Table{
+				Data: [][]mailgen.Entry{
+					{
+						{Key: "item", Value: "Golang"},
+						{Key: "description", Value: "An open source programming language"},
+					},
+					{
+						{Key: "item", Value: "Rust"},
+						{Key: "description", Value: "Fast and safe"},
+					},
+				},
+				Columns: mailgen.Columns{
+					MaxCellWidth: map[string]int{"description": 16, "item": 2},
+				},
+			},
+			expected: "Item | Description     \n-----+-----------------\nGo   | An open sourc...\nRu   | Fast and safe   \n",
+			wantErr:  false,
+		},
+		{
+			name: "table with currency columns",
+			table: mailgen.Table{
+				Data: [][]mailgen.Entry{
+					{
+						{Key: "item", Value: "Golang"},
+						{Key: "price", Value: "1099"},
+						{Key: "local", Value: "150000"},
+					},
+					{
+						{Key: "item", Value: "Refund"},
+						{Key: "price", Value: "-10.5"},
+						{Key: "local", Value: "n/a"},
+					},
+				},
+				Footer: []mailgen.Entry{
+					{Key: "item", Value: "Total"},
+					{Key: "price", Value: "1088.5"},
+				},
+				Columns: mailgen.Columns{
+					CustomAlign: map[string]string{"local": "center"},
+					Currency:    map[string]string{"price": "usd", "local": "IDR"},
+				},
+			},
+			expected: "Item   |     Price |   Local  \n" +
+				"-------+-----------+----------\n" +
+				"Golang | $1,099.00 | Rp150.000\n" +
+				"Refund |   -$10.50 |    n/a   \n" +
+				"-------+-----------+----------\n" +
+				"Total  | $1,088.50 |          \n",
+			wantErr: false,
+		},
+		{
+			name: "table with sum columns",
+			table: mailgen.Table{
+				Data: [][]mailgen.Entry{
+					{
+						{Key: "item", Value: "Golang"},
+						{Key: "qty", Value: "2"},
+						{Key: "price", Value: "10.99"},
+					},
+					{
+						{Key: "item", Value: "Mailgen"},
+						{Key: "qty", Value: "free"},
+						{Key: "price", Value: "1.9"},
+					},
+				},
+				Footer:     []mailgen.Entry{{Key: "item", Value: "Total"}, {Key: "price", Value: "0"}},
+				SumColumns: []string{"qty", "price"},
+				Columns: mailgen.Columns{
+					Currency: map[string]string{"price": "USD"},
+				},
+			},
+			expected: "Item    | Qty  |  Price\n" +
+				"--------+------+-------\n" +
+				"Golang  | 2    | $10.99\n" +
+				"Mailgen | free |  $1.90\n" +
+				"--------+------+-------\n" +
+				"Total   | 2    | $12.89\n",
+			wantErr: false,
+		},
+		{
+			name: "table with strict sum and non-numeric value",
+			table: mailgen.Table{
+				Data: [][]mailgen.Entry{
+					{{Key: "qty", Value: "2"}},
+					{{Key: "qty", Value: "free"}},
+				},
+				SumColumns: []string{"qty"},
+				StrictSum:  true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "table with spanning entries",
+			table: mailgen.Table{
+				Data: [][]mailgen.Entry{
+					{
+						{Key: "item", Value: "Golang"},
+						{Key: "qty", Value: "2"},
+						{Key: "price", Value: "$10.99"},
+					},
+					{
+						{Key: "item", Value: "Thank you!", Span: 3},
+					},
+					{
+						{Key: "qty", Value: "Shipping", Span: 2},
+						{Key: "item", Value: "Express"},
+					},
+				},
+				Footer: []mailgen.Entry{
+					{Key: "item", Value: "Total", Span: 2},
+					{Key: "price", Value: "$10.99"},
+				},
+			},
+			expected: "Item    | Qty | Price \n" +
+				"--------+-----+-------\n" +
+				"Golang  | 2   | $10.99\n" +
+				"Thank you!            \n" +
+				"Express | Shipping    \n" +
+				"--------+-----+-------\n" +
+				"Total         | $10.99\n",
+			wantErr: false,
+		},
 		{
 			name: "table with center alignment",
 			table: mailgen.Table{
@@ -404,7 +697,7 @@ func TestTable_PlainText(t *testing.T) {
 			result, err := tt.table.PlainText()
 
 			if tt.wantErr {
-				require.Error(t, err)
+				require.ErrorIs(t, err, mailgen.ErrInvalidTableValue)
 			} else {
 				require.NoError(t, err)
 				assert.Equal(t, tt.expected, result)
@@ -429,6 +722,16 @@ func TestAction_PlainText(t *testing.T) {
 			expected: "Click Here (https://example.com)",
 			wantErr:  false,
 		},
+		{
+			name: "action with icon",
+			action: mailgen.Action{
+				Text: "Get Started",
+				Link: "https://example.com",
+				Icon: "🚀",
+			},
+			expected: "🚀 Get Started (https://example.com)",
+			wantErr:  false,
+		},
 		{
 			name: "action with empty text",
 			action: mailgen.Action{
@@ -456,6 +759,16 @@ func TestAction_PlainText(t *testing.T) {
 			expected: " ()",
 			wantErr:  false,
 		},
+		{
+			name: "action with plain text override",
+			action: mailgen.Action{
+				Text:              "Click Here",
+				Link:              "https://example.com",
+				PlainTextOverride: "Tap here: https://example.com",
+			},
+			expected: "Tap here: https://example.com",
+			wantErr:  false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -495,6 +808,15 @@ func TestLine_PlainText(t *testing.T) {
 			expected: "",
 			wantErr:  false,
 		},
+		{
+			name: "line with markdown",
+			line: mailgen.Line{
+				Text:     "**Bold**, *italic*, _also italic_ and [a link](https://example.com)",
+				Markdown: true,
+			},
+			expected: "Bold, italic, also italic and a link (https://example.com)",
+			wantErr:  false,
+		},
 		{
 			name: "line with multiline text",
 			line: mailgen.Line{
@@ -518,3 +840,504 @@ func TestLine_PlainText(t *testing.T) {
 		})
 	}
 }
+
+func TestCallout_HTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		callout  mailgen.Callout
+		template string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name: "callout with text and link",
+			callout: mailgen.Callout{
+				Text:     "Heads up",
+				Link:     "https://example.com",
+				LinkText: "Learn more",
+			},
+			template: `{{define "callout"}}<div>{{.Text}} <a href="{{.Link}}">{{.LinkText}}</a></div>{{end}}`,
+			expected: `<div>Heads up <a href="https://example.com">Learn more</a></div>`,
+			wantErr:  false,
+		},
+		{
+			name: "template execution error",
+			callout: mailgen.Callout{
+				Text: "Test",
+			},
+			template: `{{define "callout"}}{{.InvalidField}}{{end}}`,
+			expected: "",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := htmltemplate.New("test").Parse(tt.template)
+			require.NoError(t, err)
+
+			result, err := tt.callout.HTML(tmpl)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Empty(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCallout_PlainText(t *testing.T) {
+	tests := []struct {
+		name     string
+		callout  mailgen.Callout
+		expected string
+	}{
+		{
+			name:     "callout with text only",
+			callout:  mailgen.Callout{Text: "Heads up"},
+			expected: "Heads up",
+		},
+		{
+			name:     "callout with link",
+			callout:  mailgen.Callout{Text: "Heads up", Link: "https://example.com"},
+			expected: "Heads up\nhttps://example.com",
+		},
+		{
+			name:     "callout with link text",
+			callout:  mailgen.Callout{Text: "Heads up", Link: "https://example.com", LinkText: "Learn more"},
+			expected: "Heads up\nLearn more: https://example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.callout.PlainText()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestImage_HTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    mailgen.Image
+		template string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name: "image with all fields",
+			image: mailgen.Image{
+				Src:    "https://example.com/banner.png",
+				Alt:    "Banner",
+				Width:  "570",
+				Height: "200",
+				Align:  "left",
+			},
+			template: `{{define "image"}}<img src="{{.Src}}" alt="{{.Alt}}" width="{{.Width}}" height="{{.Height}}" align="{{.Align}}">{{end}}`,
+			expected: `<img src="https://example.com/banner.png" alt="Banner" width="570" height="200" align="left">`,
+			wantErr:  false,
+		},
+		{
+			name: "template execution error",
+			image: mailgen.Image{
+				Src: "https://example.com/banner.png",
+			},
+			template: `{{define "image"}}{{.InvalidField}}{{end}}`,
+			expected: "",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := htmltemplate.New("test").Parse(tt.template)
+			require.NoError(t, err)
+
+			result, err := tt.image.HTML(tmpl)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Empty(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestImage_PlainText(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    mailgen.Image
+		expected string
+	}{
+		{
+			name:     "image with alt text",
+			image:    mailgen.Image{Src: "https://example.com/banner.png", Alt: "Banner"},
+			expected: "Banner",
+		},
+		{
+			name:     "image without alt text",
+			image:    mailgen.Image{Src: "https://example.com/banner.png"},
+			expected: "https://example.com/banner.png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.image.PlainText()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestTitle_HTML(t *testing.T) {
+	tmpl, err := htmltemplate.New("test").Parse(`{{define "title"}}<h1>{{.Text}}</h1>{{end}}`)
+	require.NoError(t, err)
+
+	result, err := mailgen.Title{Text: "Receipt <#123>"}.HTML(tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, "<h1>Receipt &lt;#123&gt;</h1>", result)
+}
+
+func TestTitle_PlainText(t *testing.T) {
+	result, err := mailgen.Title{Text: "Your receipt"}.PlainText()
+	require.NoError(t, err)
+	assert.Equal(t, "YOUR RECEIPT", result)
+}
+
+func TestSection_HTML(t *testing.T) {
+	tmpl, err := htmltemplate.New("test").Parse(`{{define "section"}}<h2>{{.Text}}</h2>{{end}}`)
+	require.NoError(t, err)
+
+	result, err := mailgen.Section{Text: "Usage & billing"}.HTML(tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, "<h2>Usage &amp; billing</h2>", result)
+}
+
+func TestSection_PlainText(t *testing.T) {
+	result, err := mailgen.Section{Text: "Résumé"}.PlainText()
+	require.NoError(t, err)
+	assert.Equal(t, "Résumé\n======", result)
+}
+
+func TestAlert_HTML(t *testing.T) {
+	tmpl, err := htmltemplate.New("test").Parse(
+		`{{define "alert"}}<td style="border-color: {{.Color}}" bgcolor="{{.Background}}">{{.Text}}</td>{{end}}`,
+	)
+	require.NoError(t, err)
+
+	result, err := mailgen.Alert{Text: "Payment failed", Level: mailgen.AlertDanger}.HTML(tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, `<td style="border-color: #FF6136" bgcolor="#FFEFEB">Payment failed</td>`, result)
+
+	result, err = mailgen.Alert{Text: "Heads up", Level: "unknown"}.HTML(tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, `<td style="border-color: #3869D4" bgcolor="#EEF3FC">Heads up</td>`, result,
+		"Unknown levels should be rendered as info")
+}
+
+func TestAlert_PlainText(t *testing.T) {
+	tests := []struct {
+		level    string
+		expected string
+	}{
+		{level: mailgen.AlertInfo, expected: "[INFO] Your password was changed"},
+		{level: mailgen.AlertSuccess, expected: "[SUCCESS] Your password was changed"},
+		{level: mailgen.AlertWarning, expected: "[WARNING] Your password was changed"},
+		{level: mailgen.AlertDanger, expected: "[DANGER] Your password was changed"},
+		{level: "", expected: "[INFO] Your password was changed"},
+	}
+	for _, tt := range tests {
+		result, err := mailgen.Alert{Text: "Your password was changed", Level: tt.level}.PlainText()
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, result)
+	}
+}
+
+func TestDivider_HTML(t *testing.T) {
+	tmpl, err := htmltemplate.New("test").Parse(`{{define "divider"}}<hr>{{end}}`)
+	require.NoError(t, err)
+
+	result, err := mailgen.Divider{}.HTML(tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, "<hr>", result)
+
+	tmpl, err = htmltemplate.New("test").Parse(`{{define "line"}}<p></p>{{end}}`)
+	require.NoError(t, err)
+
+	result, err = mailgen.Divider{}.HTML(tmpl)
+	require.Error(t, err, "HTML should fail when the theme does not define a divider template")
+	assert.Empty(t, result)
+}
+
+func TestDivider_PlainText(t *testing.T) {
+	result, err := mailgen.Divider{}.PlainText()
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("-", 40), result)
+}
+
+func TestCoupon_HTML(t *testing.T) {
+	tmpl, err := htmltemplate.New("test").Parse(
+		`{{define "coupon"}}<div>{{.Code}}{{if .Description}}<p>{{.Description}}</p>{{end}}</div>{{end}}`,
+	)
+	require.NoError(t, err)
+
+	result, err := mailgen.Coupon{Code: "SAVE10", Description: "10% off"}.HTML(tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, "<div>SAVE10<p>10% off</p></div>", result)
+
+	tmpl, err = htmltemplate.New("test").Parse(`{{define "coupon"}}{{.InvalidField}}{{end}}`)
+	require.NoError(t, err)
+
+	result, err = mailgen.Coupon{Code: "SAVE10"}.HTML(tmpl)
+	require.Error(t, err)
+	assert.Empty(t, result)
+}
+
+func TestCoupon_PlainText(t *testing.T) {
+	tests := []struct {
+		name     string
+		coupon   mailgen.Coupon
+		expected string
+	}{
+		{
+			name:     "coupon with code only",
+			coupon:   mailgen.Coupon{Code: "SAVE10"},
+			expected: "**********\n[ SAVE10 ]\n**********",
+		},
+		{
+			name:     "coupon with description",
+			coupon:   mailgen.Coupon{Code: "SAVE10", Description: "10% off your next order"},
+			expected: "**********\n[ SAVE10 ]\n**********\n10% off your next order",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.coupon.PlainText()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestShipping_HTML(t *testing.T) {
+	tmpl, err := htmltemplate.New("test").Parse(
+		`{{define "shipping"}}<div>{{.EstimatedDelivery}}|{{.Carrier}}|{{.TrackingNumber}}</div>{{end}}`,
+	)
+	require.NoError(t, err)
+
+	shipping := mailgen.Shipping{Carrier: "UPS", TrackingNumber: "1Z999", EstimatedDelivery: "Tuesday, Jan 9"}
+	result, err := shipping.HTML(tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, "<div>Tuesday, Jan 9|UPS|1Z999</div>", result)
+
+	tmpl, err = htmltemplate.New("test").Parse(`{{define "shipping"}}{{.InvalidField}}{{end}}`)
+	require.NoError(t, err)
+
+	result, err = shipping.HTML(tmpl)
+	require.Error(t, err)
+	assert.Empty(t, result)
+}
+
+func TestShipping_PlainText(t *testing.T) {
+	tests := []struct {
+		name     string
+		shipping mailgen.Shipping
+		expected string
+	}{
+		{
+			name: "shipping with all details",
+			shipping: mailgen.Shipping{
+				Carrier:           "UPS",
+				TrackingNumber:    "1Z999",
+				TrackingURL:       "https://example.com/track",
+				EstimatedDelivery: "Tuesday, Jan 9",
+			},
+			expected: "Arrives Tuesday, Jan 9\nCarrier: UPS\nTracking number: 1Z999 (https://example.com/track)",
+		},
+		{
+			name:     "shipping with tracking number only",
+			shipping: mailgen.Shipping{TrackingNumber: "1Z999"},
+			expected: "Tracking number: 1Z999",
+		},
+		{
+			name:     "shipping with tracking URL only",
+			shipping: mailgen.Shipping{TrackingURL: "https://example.com/track"},
+			expected: "Track your package: https://example.com/track",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.shipping.PlainText()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRawHTML(t *testing.T) {
+	raw := mailgen.RawHTML{Content: "<p>Hello <b>world</b></p>"}
+
+	result, err := raw.HTML(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Hello <b>world</b></p>", result)
+
+	result, err = raw.PlainText()
+	require.NoError(t, err)
+	assert.Empty(t, result)
+}
+
+func TestDigestItem(t *testing.T) {
+	tmpl, err := htmltemplate.New("test").Parse(
+		`{{define "line"}}<p>{{.Text}}</p>{{end}}` +
+			`{{define "digest_item"}}<div><h3>{{.Title}}</h3>{{range .Content}}{{.}}{{end}}</div>{{end}}`,
+	)
+	require.NoError(t, err)
+	item := mailgen.DigestItem{
+		Title: "Weekly stats",
+		Components: []mailgen.Component{
+			mailgen.Line{Text: "12 new users"},
+			&mailgen.RawHTML{Content: "<hr>"},
+		},
+	}
+
+	result, err := item.HTML(tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, "<div><h3>Weekly stats</h3><p>12 new users</p><hr></div>", result)
+
+	result, err = item.PlainText()
+	require.NoError(t, err)
+	assert.Equal(t, "Weekly stats\n============\n\n12 new users", result)
+
+	item.Components = append(item.Components, &mailgen.Divider{})
+	_, err = item.HTML(tmpl)
+	require.ErrorContains(t, err, "component[2] (*mailgen.Divider)")
+}
+
+func TestList_HTML(t *testing.T) {
+	tmpl, err := htmltemplate.New("test").Parse(
+		`{{define "list"}}{{if .Ordered}}<ol>{{else}}<ul>{{end}}{{range .Items}}<li>{{.}}</li>{{end}}{{end}}`,
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		list     mailgen.List
+		expected string
+	}{
+		{
+			name:     "unordered list",
+			list:     mailgen.List{Items: []string{"One", "Two"}},
+			expected: "<ul><li>One</li><li>Two</li>",
+		},
+		{
+			name:     "ordered list",
+			list:     mailgen.List{Items: []string{"One"}, Ordered: true},
+			expected: "<ol><li>One</li>",
+		},
+		{
+			name:     "items are escaped",
+			list:     mailgen.List{Items: []string{"<b>bold</b>"}},
+			expected: "<ul><li>&lt;b&gt;bold&lt;/b&gt;</li>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.list.HTML(tmpl)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestList_PlainText(t *testing.T) {
+	tests := []struct {
+		name     string
+		list     mailgen.List
+		expected string
+	}{
+		{
+			name:     "unordered list",
+			list:     mailgen.List{Items: []string{"One", "Two"}},
+			expected: "- One\n- Two",
+		},
+		{
+			name:     "ordered list",
+			list:     mailgen.List{Items: []string{"One", "Two"}, Ordered: true},
+			expected: "1. One\n2. Two",
+		},
+		{
+			name:     "multi-line items are indented",
+			list:     mailgen.List{Items: []string{"First line\nSecond line"}},
+			expected: "- First line\n  Second line",
+		},
+		{
+			name: "multi-line items are indented past wide numbers",
+			list: mailgen.List{
+				Items:   []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "Ten\nmore"},
+				Ordered: true,
+			},
+			expected: "1. 1\n2. 2\n3. 3\n4. 4\n5. 5\n6. 6\n7. 7\n8. 8\n9. 9\n10. Ten\n    more",
+		},
+		{
+			name:     "empty list",
+			list:     mailgen.List{},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.list.PlainText()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestActionGroup_HTML(t *testing.T) {
+	tmpl, err := htmltemplate.New("test").Parse(
+		`{{define "button_group"}}<div>{{range .Actions}}<a href="{{.Link}}">{{.Text}}</a>{{end}}</div>{{end}}`,
+	)
+	require.NoError(t, err)
+
+	group := mailgen.ActionGroup{Actions: []*mailgen.Action{
+		{Text: "Yes", Link: "https://example.com/yes"},
+		{Text: "No", Link: "https://example.com/no"},
+	}}
+	result, err := group.HTML(tmpl)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`<div><a href="https://example.com/yes">Yes</a><a href="https://example.com/no">No</a></div>`,
+		result,
+	)
+
+	tmpl, err = htmltemplate.New("test").Parse(`{{define "button_group"}}{{.InvalidField}}{{end}}`)
+	require.NoError(t, err)
+
+	result, err = group.HTML(tmpl)
+	require.Error(t, err)
+	assert.Empty(t, result)
+}
+
+func TestActionGroup_PlainText(t *testing.T) {
+	group := mailgen.ActionGroup{Actions: []*mailgen.Action{
+		{Text: "Yes", Link: "https://example.com/yes"},
+		{Text: "No", Link: "https://example.com/no", PlainTextOverride: "Decline: https://example.com/no"},
+	}}
+	result, err := group.PlainText()
+	require.NoError(t, err)
+	assert.Equal(t, "Yes (https://example.com/yes)\nDecline: https://example.com/no", result)
+}
@@ -0,0 +1,58 @@
+package mailgen
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// currencyFormat describes how amounts of a currency are written.
+type currencyFormat struct {
+	symbol    string
+	decimals  int
+	thousands string
+	decimal   string
+}
+
+// currencyFormats contains the formats of the currencies supported by Columns.Currency, by ISO 4217 code.
+// Other currencies are written with their code and two decimals, e.g. "CHF 1,099.00".
+var currencyFormats = map[string]currencyFormat{
+	"USD": {symbol: "$", decimals: 2, thousands: ",", decimal: "."},
+	"EUR": {symbol: "€", decimals: 2, thousands: ",", decimal: "."},
+	"GBP": {symbol: "£", decimals: 2, thousands: ",", decimal: "."},
+	"JPY": {symbol: "¥", decimals: 0, thousands: ",", decimal: "."},
+	"INR": {symbol: "₹", decimals: 2, thousands: ",", decimal: "."},
+	"IDR": {symbol: "Rp", decimals: 0, thousands: ".", decimal: ","},
+}
+
+// formatCurrency formats the numeric value as an amount in the currency with the given code,
+// e.g. "1099" in "USD" as "$1,099.00". Values that are not numbers are returned unchanged.
+func formatCurrency(value, code string) string {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsInf(amount, 0) || math.IsNaN(amount) {
+		return value
+	}
+	code = strings.ToUpper(strings.TrimSpace(code))
+	format, ok := currencyFormats[code]
+	if !ok {
+		format = currencyFormat{symbol: code + " ", decimals: 2, thousands: ",", decimal: "."}
+	}
+
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', format.decimals, 64)
+	integer, fraction, _ := strings.Cut(digits, ".")
+	var sb strings.Builder
+	if amount < 0 && strings.Trim(digits, "0.") != "" {
+		sb.WriteString("-")
+	}
+	sb.WriteString(format.symbol)
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			sb.WriteString(format.thousands)
+		}
+		sb.WriteRune(digit)
+	}
+	if fraction != "" {
+		sb.WriteString(format.decimal + fraction)
+	}
+	return sb.String()
+}
@@ -0,0 +1,267 @@
+package mailgen
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+)
+
+// RenderEML renders msg in the RFC 5322 message format, e.g. to archive it as an .eml file or to
+// snapshot-test it. The output is not a complete message: it has no Date header, which RFC 5322 requires,
+// so the mail client must add it before sending.
+//
+// The body is a multipart/alternative part with the plain text, AMP (if enabled) and HTML content.
+// Bcc addresses are not included in the headers. The Date header, and the Message-ID header unless
+// set via Builder.MessageID or Builder.MessageIDDomain, are left to the mail client, so the output is
+// the same for the same message. Images referenced by ContentIDs and attachments are not included.
+// Header lines are folded to at most 78 characters where possible.
+//
+// An error wrapping ErrInvalidAddress is returned if an address cannot be parsed or contains a line
+// break, and an error wrapping ErrInvalidHeader if the key of a custom header is not a valid field name
+// or its value cannot be folded.
+//
+// Example usage:
+//
+//	message, err := email.Build()
+//	if err != nil {
+//		return err
+//	}
+//	eml, err := mailgen.RenderEML(message)
+//	if err != nil {
+//		return err
+//	}
+//	os.WriteFile("welcome.eml", eml, 0o644)
+func RenderEML(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	parts := []emlPart{{contentType: "text/plain", content: msg.PlainText()}}
+	if amp := msg.AMPHTML(); amp != "" {
+		parts = append(parts, emlPart{contentType: "text/x-amp-html", content: amp})
+	}
+	parts = append(parts, emlPart{contentType: "text/html", content: msg.HTML()})
+
+	boundary := emlBoundary(parts)
+	if err := writeEMLHeaders(&buf, msg); err != nil {
+		return nil, err
+	}
+	if err := writeEMLHeader(&buf, "Content-Type", mime.FormatMediaType("multipart/alternative",
+		map[string]string{"boundary": boundary})); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\r\n")
+
+	mw := multipart.NewWriter(&buf)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return nil, err
+	}
+	for _, part := range parts {
+		if err := part.write(mw); err != nil {
+			return nil, fmt.Errorf("%s part: %w", part.contentType, err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type emlPart struct {
+	contentType string
+	content     string
+}
+
+func (p emlPart) write(mw *multipart.Writer) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType(p.contentType, map[string]string{"charset": "UTF-8"}))
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	w, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(w)
+	if _, err := io.WriteString(qp, p.content); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// emlBoundary returns a multipart boundary derived from the content of the parts,
+// so rendering the same message always produces the same output.
+func emlBoundary(parts []emlPart) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part.content))
+	}
+	return "mailgen-" + hex.EncodeToString(hash.Sum(nil)[:12])
+}
+
+func writeEMLHeaders(buf *bytes.Buffer, msg Message) error {
+	var addresses []emlAddressHeader
+	if msg.From().Address != "" {
+		addresses = append(addresses, emlAddressHeader{"From", []string{msg.FromString()}})
+	}
+	if msg.Sender() != nil {
+		addresses = append(addresses, emlAddressHeader{"Sender", []string{msg.SenderString()}})
+	}
+	if msg.ReplyTo() != nil {
+		addresses = append(addresses, emlAddressHeader{"Reply-To", []string{msg.ReplyToString()}})
+	}
+	if len(msg.To()) > 0 {
+		addresses = append(addresses, emlAddressHeader{"To", msg.To()})
+	}
+	if len(msg.Cc()) > 0 {
+		addresses = append(addresses, emlAddressHeader{"Cc", msg.Cc()})
+	}
+	for _, header := range addresses {
+		value, err := formatEMLAddresses(header.key, header.addresses)
+		if err != nil {
+			return err
+		}
+		if err := writeEMLHeader(buf, header.key, value); err != nil {
+			return err
+		}
+	}
+	if err := writeEMLHeader(buf, "Subject", encodeEMLText(msg.Subject())); err != nil {
+		return err
+	}
+	if err := writeEMLHeader(buf, "MIME-Version", "1.0"); err != nil {
+		return err
+	}
+
+	headers := msg.Headers()
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if !validEMLHeaderKey(key) {
+			return fmt.Errorf("%w: invalid key %q", ErrInvalidHeader, key)
+		}
+		for _, value := range headers[key] {
+			if err := writeEMLHeader(buf, key, mime.QEncoding.Encode("UTF-8", value)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type emlAddressHeader struct {
+	key       string
+	addresses []string
+}
+
+// validEMLHeaderKey reports whether key is a valid RFC 5322 field name: printable ASCII characters
+// other than the colon.
+func validEMLHeaderKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 33 || key[i] > 126 || key[i] == ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// emlLineLength is the length header lines are folded at, as recommended by RFC 5322. Lines are never
+// longer than emlMaxLineLength, the limit of RFC 5322, excluding the CRLF.
+const (
+	emlLineLength    = 78
+	emlMaxLineLength = 998
+)
+
+// writeEMLHeader writes the header field, folding the value at its spaces so that the lines are at most
+// emlLineLength characters long where possible. It returns an error wrapping ErrInvalidHeader if a word
+// of the value is too long to fit in a line.
+func writeEMLHeader(buf *bytes.Buffer, key, value string) error {
+	buf.WriteString(key + ":")
+	line := len(key) + 1
+	for i, word := range strings.Split(value, " ") {
+		if i > 0 && line+1+len(word) > emlLineLength {
+			buf.WriteString("\r\n")
+			line = 0
+		}
+		if line+1+len(word) > emlMaxLineLength {
+			return fmt.Errorf("%w: value of %q is too long to fold", ErrInvalidHeader, key)
+		}
+		buf.WriteString(" " + word)
+		line += 1 + len(word)
+	}
+	buf.WriteString("\r\n")
+	return nil
+}
+
+// encodeEMLText returns value encoded for an unstructured header field such as Subject. It is Q-encoded
+// if it contains characters that need encoding or words too long to be folded, and left as it is otherwise.
+func encodeEMLText(value string) string {
+	encoded := mime.QEncoding.Encode("UTF-8", value)
+	if encoded != value {
+		return encoded
+	}
+	for _, word := range strings.Split(value, " ") {
+		if len(word) > emlLineLength-len(" ") {
+			return qEncodeWords(value)
+		}
+	}
+	return value
+}
+
+// qEncodeWords returns value as Q-encoded words of at most 75 characters, as mime.QEncoding does for
+// values that need encoding.
+func qEncodeWords(value string) string {
+	const prefix, suffix = "=?UTF-8?q?", "?="
+	const maxText = 75 - len(prefix) - len(suffix)
+	var words []string
+	var word strings.Builder
+	for _, r := range value {
+		var encoded strings.Builder
+		for _, c := range []byte(string(r)) {
+			switch {
+			case c == ' ':
+				encoded.WriteByte('_')
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', strings.IndexByte("!*+-/", c) >= 0:
+				encoded.WriteByte(c)
+			default:
+				fmt.Fprintf(&encoded, "=%02X", c)
+			}
+		}
+		if word.Len()+encoded.Len() > maxText {
+			words = append(words, prefix+word.String()+suffix)
+			word.Reset()
+		}
+		word.WriteString(encoded.String())
+	}
+	if word.Len() > 0 {
+		words = append(words, prefix+word.String()+suffix)
+	}
+	return strings.Join(words, " ")
+}
+
+// formatEMLAddresses returns the addresses of the header field with their display names encoded.
+// It returns an error wrapping ErrInvalidAddress if an address cannot be parsed or contains a line break,
+// as writing it as it is could inject header fields.
+func formatEMLAddresses(field string, addresses []string) (string, error) {
+	formatted := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if strings.ContainsAny(address, "\r\n") {
+			return "", fmt.Errorf("%w: invalid %s address %q", ErrInvalidAddress, field, address)
+		}
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid %s address %q", ErrInvalidAddress, field, address)
+		}
+		formatted = append(formatted, parsed.String())
+	}
+	return strings.Join(formatted, ", "), nil
+}
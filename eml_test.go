@@ -0,0 +1,186 @@
+package mailgen_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/akfaiz/go-mailgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderEML(t *testing.T) {
+	message, err := mailgen.New().
+		Subject("Your order — #1234").
+		From("shop@example.com", "Shöp").
+		ReplyTo("support@example.com").
+		Sender("notifications@example.com").
+		To("John <john@example.com>", "jane@example.com").
+		Cc("sales@example.com").
+		Bcc("audit@example.com").
+		Header("X-Campaign-ID", "spring-sale").
+		Line("Your order has shipped.").
+		Build()
+	require.NoError(t, err)
+
+	eml, err := mailgen.RenderEML(message)
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(eml))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Your order — #1234", subject)
+
+	from, err := parsed.Header.AddressList("From")
+	require.NoError(t, err)
+	assert.Equal(t, []*mail.Address{{Name: "Shöp", Address: "shop@example.com"}}, from)
+	assert.Equal(t, "<support@example.com>", parsed.Header.Get("Reply-To"))
+	assert.Equal(t, "<notifications@example.com>", parsed.Header.Get("Sender"))
+	assert.Equal(t, `"John" <john@example.com>, <jane@example.com>`, parsed.Header.Get("To"))
+	assert.Equal(t, "<sales@example.com>", parsed.Header.Get("Cc"))
+	assert.Empty(t, parsed.Header.Get("Bcc"))
+	assert.Equal(t, "spring-sale", parsed.Header.Get("X-Campaign-ID"))
+	assert.Equal(t, "1.0", parsed.Header.Get("MIME-Version"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	var contentTypes []string
+	var contents []string
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(part)
+		require.NoError(t, err)
+		contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+		contents = append(contents, strings.ReplaceAll(string(content), "\r\n", "\n"))
+	}
+	assert.Equal(t, []string{"text/plain; charset=UTF-8", "text/html; charset=UTF-8"}, contentTypes)
+	assert.Equal(t, []string{message.PlainText(), message.HTML()}, contents)
+
+	again, err := mailgen.RenderEML(message)
+	require.NoError(t, err)
+	assert.Equal(t, eml, again, "Rendering the same message should produce the same output")
+}
+
+func TestRenderEML_AMP(t *testing.T) {
+	message, err := mailgen.New().AMP(true).Line("Your order has shipped.").Build()
+	require.NoError(t, err)
+
+	eml, err := mailgen.RenderEML(message)
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(eml))
+	require.NoError(t, err)
+	_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+
+	var contentTypes []string
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+	}
+	assert.Equal(t, []string{
+		"text/plain; charset=UTF-8",
+		"text/x-amp-html; charset=UTF-8",
+		"text/html; charset=UTF-8",
+	}, contentTypes)
+}
+
+func TestRenderEML_InvalidHeaderKey(t *testing.T) {
+	for _, key := range []string{"X-Campaign ID", "X-Injected:\r\nBcc", "X-Tagé"} {
+		message, err := mailgen.New().Header(key, "value").Line("Hello").Build()
+		require.NoError(t, err)
+
+		_, err = mailgen.RenderEML(message)
+		require.ErrorIs(t, err, mailgen.ErrInvalidHeader, "key %q", key)
+	}
+}
+
+func TestRenderEML_InvalidAddress(t *testing.T) {
+	injected := "victim@example.com\r\nBcc: spy@evil.com"
+	builders := map[string]*mailgen.Builder{
+		"To":       mailgen.New().To(injected),
+		"From":     mailgen.New().From(injected),
+		"Reply-To": mailgen.New().ReplyTo(injected),
+		"Cc":       mailgen.New().Cc("not an address"),
+	}
+	for field, builder := range builders {
+		message, err := builder.Line("Hello").Build()
+		require.NoError(t, err)
+
+		eml, err := mailgen.RenderEML(message)
+		require.ErrorIs(t, err, mailgen.ErrInvalidAddress, field)
+		assert.Nil(t, eml, field)
+	}
+}
+
+func TestRenderEML_FoldsLongHeaders(t *testing.T) {
+	recipients := make([]string, 60)
+	for i := range recipients {
+		recipients[i] = fmt.Sprintf("Recipient %d <recipient-%d@example.com>", i, i)
+	}
+	subject := "Your order " + strings.Repeat("é", 60) + " " + strings.Repeat("x", 120)
+	message, err := mailgen.New().
+		Subject(subject).
+		To(recipients[0], recipients[1:]...).
+		Header("X-Note", strings.Repeat("word ", 40)).
+		Line("Hello").
+		Build()
+	require.NoError(t, err)
+
+	eml, err := mailgen.RenderEML(message)
+	require.NoError(t, err)
+
+	header, _, _ := strings.Cut(string(eml), "\r\n\r\n")
+	for _, line := range strings.Split(header, "\r\n") {
+		if strings.HasPrefix(line, " ") {
+			assert.LessOrEqual(t, len(line), 78, "Header line %q should be folded", line)
+		}
+		assert.LessOrEqual(t, len(line), 998, "Header line %q is longer than RFC 5322 allows", line)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(eml))
+	require.NoError(t, err)
+	to, err := parsed.Header.AddressList("To")
+	require.NoError(t, err)
+	assert.Len(t, to, 60)
+	assert.Equal(t, "Recipient 59", to[59].Name)
+	decoded, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, subject, decoded)
+
+	subject = "Reset your password: https://example.com/reset?token=" + strings.Repeat("a1_=", 30)
+	message, err = mailgen.New().Subject(subject).Build()
+	require.NoError(t, err)
+	eml, err = mailgen.RenderEML(message)
+	require.NoError(t, err)
+	parsed, err = mail.ReadMessage(bytes.NewReader(eml))
+	require.NoError(t, err)
+	decoded, err = new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, subject, decoded, "Subjects with long words should be encoded to be folded")
+
+	message, err = mailgen.New().Header("X-Token", strings.Repeat("x", 1000)).Build()
+	require.NoError(t, err)
+	_, err = mailgen.RenderEML(message)
+	require.ErrorIs(t, err, mailgen.ErrInvalidHeader)
+}
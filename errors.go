@@ -7,4 +7,22 @@ var (
 	ErrInvalidThemeName = errors.New("mailgen: theme name cannot be empty")
 	// ErrNilHTMLTemplate indicates a theme has no HTML template.
 	ErrNilHTMLTemplate = errors.New("mailgen: theme HTML template cannot be nil")
+	// ErrMissingThemeTemplate indicates a theme HTML template does not define a required sub-template.
+	ErrMissingThemeTemplate = errors.New("mailgen: theme HTML template is missing a required template")
+	// ErrInvalidLocale indicates an empty locale language was provided.
+	ErrInvalidLocale = errors.New("mailgen: locale language cannot be empty")
+	// ErrInvalidAddress indicates an email address could not be parsed when StrictAddresses is enabled.
+	ErrInvalidAddress = errors.New("mailgen: invalid email address")
+	// ErrInvalidLink indicates an action link is malformed or its scheme is not allowed when StrictLinks is enabled.
+	ErrInvalidLink = errors.New("mailgen: invalid action link")
+	// ErrInvalidHeader indicates a custom header key is not a valid RFC 5322 field name when rendering an EML message.
+	ErrInvalidHeader = errors.New("mailgen: invalid header")
+	// ErrInvalidTableValue indicates a value in a Table.SumColumns column is not a number when StrictSum is enabled.
+	ErrInvalidTableValue = errors.New("mailgen: invalid table value")
+	// ErrNoSubject indicates the email message has no subject.
+	ErrNoSubject = errors.New("mailgen: message must have a subject")
+	// ErrNoRecipients indicates the email message has no To, Cc, or Bcc address.
+	ErrNoRecipients = errors.New("mailgen: message must have at least one recipient")
+	// ErrUnknownComponentType indicates a component cannot be converted to or from a ComponentSpec.
+	ErrUnknownComponentType = errors.New("mailgen: unknown component type")
 )
@@ -0,0 +1,212 @@
+package mailgen
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCachedHTML_EquivalentToFullPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder func() *Builder
+	}{
+		{
+			name: "receipt",
+			builder: func() *Builder {
+				return New().
+					Preheader("Your receipt").
+					Name("John").
+					Line("Thanks for your purchase.").
+					Table(Table{
+						Data: [][]Entry{
+							{{Key: "Item", Value: "Widget"}, {Key: "Price", Value: "$10"}},
+							{{Key: "Item", Value: "Gadget & Co"}, {Key: "Price", Value: "$20"}},
+						},
+						Columns: Columns{CustomAlign: map[string]string{"Price": "right"}},
+					}).
+					Action("View order", "https://example.com/orders?id=1&ref=mail").
+					Line("If you have any questions, reply to this email.")
+			},
+		},
+		{
+			name: "special characters",
+			builder: func() *Builder {
+				return New().
+					Name(`O'Brien & "Sons"`).
+					Markdown("**Bold** and [a link](https://example.com/?a=1&b=2)").
+					Callout(Callout{Text: `<Careful> & "quoted"`, Link: "https://example.com", LinkText: "Learn more"}).
+					Image(Image{Src: "https://example.com/logo.png?w=1&h=2", Alt: `Logo "alt"`}).
+					Coupon("SAVE-20").
+					Line("@@mailgen:slot:0@@")
+			},
+		},
+		{
+			name: "plain theme with lists and footer",
+			builder: func() *Builder {
+				return New().
+					Theme("plain").
+					TextDirection("rtl").
+					Product(Product{Name: "Acme", Link: "https://acme.example", Copyright: "© Acme"}).
+					Social([]SocialLink{{Name: "X", URL: "https://x.com/acme"}}).
+					Unsubscribe("https://acme.example/unsubscribe").
+					List([]string{"One", "Two & three"}).
+					Divider().
+					OrderedList([]string{"First", "Second"})
+			},
+		},
+		{
+			name: "only lines",
+			builder: func() *Builder {
+				return New().Name("John").Line("Your report is ready.").Line("Thanks for using our service.")
+			},
+		},
+		{
+			name: "lines with special characters",
+			builder: func() *Builder {
+				return New().
+					Name(`O'Brien & "Sons"`).
+					Line(`Use <b>bold</b> & 'quotes' "here" + 1 = 2 &amp; &nbsp;`).
+					Line("Unicode: héllo wörld 👋\nSecond line\twith tab").
+					Line("Windows\r\nline breaks").
+					Line("@@mailgen:slot:0@@")
+			},
+		},
+		{
+			name: "plain theme with preheader and rtl",
+			builder: func() *Builder {
+				return New().
+					Theme("plain").
+					TextDirection("rtl").
+					Preheader("Quick update").
+					Name("جون").
+					Line("هذا هو عنوان البريد الإلكتروني الخاص بك")
+			},
+		},
+		{
+			name: "without greeting",
+			builder: func() *Builder {
+				return New().Bare().Line("Machine-readable body")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.builder()
+			tmpl := resolveTheme(b.theme).HTML
+			full, err := b.renderHTML(tmpl)
+			require.NoError(t, err)
+
+			cached, err := b.generateCachedHTML(tmpl)
+			require.NoError(t, err)
+			assert.Equal(t, full, cached)
+
+			cached, err = b.generateCachedHTML(tmpl)
+			require.NoError(t, err)
+			assert.Equal(t, full, cached, "Cached skeleton should produce the same output")
+		})
+	}
+}
+
+func TestGenerateHTML_PersonalizedLinesShareSkeleton(t *testing.T) {
+	inlinedSkeletons = newSkeletonCache(maxInlinedSkeletons)
+	for i := range 5 {
+		_, err := New().
+			Preheader(fmt.Sprintf("Update #%d", i)).
+			Name(fmt.Sprintf("User %d", i)).
+			ViewInBrowser(fmt.Sprintf("https://example.com/emails/%d", i)).
+			Line(fmt.Sprintf("Your report #%d is ready.", i)).
+			generateHTML()
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, inlinedSkeletons.len(), "Personalized texts should not create new skeletons")
+}
+
+func TestInlineSkeleton_EvictsLeastRecentlyUsed(t *testing.T) {
+	options := New().premailerOpts()
+	key := func(raw []byte) skeletonKey {
+		return skeletonKey{hash: sha256.Sum256(raw), options: *options}
+	}
+	document := func(i int) []byte {
+		return []byte(fmt.Sprintf(`<html><head><style>p{color:red}</style></head><body><p id="p%d">x</p></body></html>`, i))
+	}
+
+	first := document(0)
+	_, err := inlineSkeleton(first, options)
+	require.NoError(t, err)
+	for i := 1; i <= maxInlinedSkeletons; i++ {
+		_, err := inlineSkeleton(document(i), options)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, maxInlinedSkeletons, inlinedSkeletons.len())
+	_, ok := inlinedSkeletons.get(key(first))
+	assert.False(t, ok, "The least recently used skeleton should be evicted")
+
+	second := document(1)
+	_, ok = inlinedSkeletons.get(key(second))
+	require.True(t, ok)
+
+	layout := []byte(`<html><body><table><tr><td>New layout</td></tr></table></body></html>`)
+	_, err = inlineSkeleton(layout, options)
+	require.NoError(t, err)
+	_, ok = inlinedSkeletons.get(key(layout))
+	assert.True(t, ok, "A new layout should still be cached once the cache is full")
+	_, ok = inlinedSkeletons.get(key(second))
+	assert.True(t, ok, "Recently used skeletons should not be evicted")
+	_, ok = inlinedSkeletons.get(key(document(2)))
+	assert.False(t, ok)
+}
+
+func BenchmarkGenerateHTML_LinesCachedPath(b *testing.B) {
+	builder := New().Name("John").Line("Your report is ready.").Line("Thanks for using our service.")
+	for range b.N {
+		if _, err := builder.generateHTML(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGenerateHTML_LinesFullPath(b *testing.B) {
+	builder := New().Name("John").Line("Your report is ready.").Line("Thanks for using our service.")
+	tmpl := resolveTheme(builder.theme).HTML
+	for range b.N {
+		if _, err := builder.renderHTML(tmpl); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkReceipt() *Builder {
+	return New().
+		Name("John").
+		Line("Thanks for your purchase.").
+		Table(Table{Data: [][]Entry{
+			{{Key: "Item", Value: "Widget"}, {Key: "Price", Value: "$10"}},
+			{{Key: "Item", Value: "Gadget"}, {Key: "Price", Value: "$20"}},
+		}}).
+		Action("View order", "https://example.com/orders/1").
+		Line("If you have any questions, reply to this email.")
+}
+
+func BenchmarkGenerateHTML_CachedPath(b *testing.B) {
+	builder := benchmarkReceipt()
+	for range b.N {
+		if _, err := builder.generateHTML(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGenerateHTML_FullPath(b *testing.B) {
+	builder := benchmarkReceipt()
+	tmpl := resolveTheme(builder.theme).HTML
+	for range b.N {
+		if _, err := builder.renderHTML(tmpl); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
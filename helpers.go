@@ -0,0 +1,169 @@
+package mailgen
+
+import (
+	"fmt"
+	"time"
+)
+
+// PasswordResetConfig configures the password reset email created by PasswordReset.
+// Empty text fields fall back to standard English texts.
+type PasswordResetConfig struct {
+	// ResetURL is the URL of the password reset page.
+	ResetURL string
+	// Name is the optional name of the recipient used in the greeting.
+	Name string
+	// ExpiresIn is how long the reset link is valid. If zero, no expiry notice is added.
+	ExpiresIn time.Duration
+	// Subject is the subject of the email. Default is "Reset Your Password".
+	Subject string
+	// Intro is the line displayed before the reset button.
+	Intro string
+	// ActionText is the text of the reset button. Default is "Reset Password".
+	ActionText string
+	// ExpiryText is the expiry notice displayed after the reset button.
+	// Default is generated from ExpiresIn, e.g. "This password reset link will expire in 60 minutes.".
+	ExpiryText string
+	// Outro is the line displayed at the end, advising the recipient what to do if they did not request the reset.
+	Outro string
+}
+
+// PasswordReset creates a new Builder pre-populated with a standard password reset email:
+// an intro line, a reset button, an optional expiry notice, and a security note.
+//
+// The returned Builder can be customized further like any other Builder.
+//
+// Example usage:
+//
+//	message, err := mailgen.PasswordReset(mailgen.PasswordResetConfig{
+//		ResetURL:  "https://example.com/reset-password?token=abc",
+//		Name:      "John Doe",
+//		ExpiresIn: time.Hour,
+//	}).
+//		To("john@example.com").
+//		Build()
+func PasswordReset(cfg PasswordResetConfig) *Builder {
+	b := New().
+		Subject(stringOr(cfg.Subject, "Reset Your Password")).
+		Name(cfg.Name).
+		Line(stringOr(
+			cfg.Intro,
+			"You are receiving this email because we received a password reset request for your account.",
+		)).
+		Action(stringOr(cfg.ActionText, "Reset Password"), cfg.ResetURL)
+
+	expiryText := cfg.ExpiryText
+	if expiryText == "" && cfg.ExpiresIn > 0 {
+		expiryText = fmt.Sprintf("This password reset link will expire in %s.", formatDuration(cfg.ExpiresIn))
+	}
+	if expiryText != "" {
+		b.Line(expiryText)
+	}
+
+	return b.Outro(stringOr(cfg.Outro, "If you did not request a password reset, no further action is required."))
+}
+
+// VerificationConfig configures the email verification email created by EmailVerification.
+// Set VerifyURL for link-based verification, Code for code-based verification, or both.
+// With neither, EmailVerification adds no verification content.
+// Empty text fields fall back to standard English texts.
+type VerificationConfig struct {
+	// VerifyURL is the URL that verifies the email address when visited.
+	VerifyURL string
+	// Code is the verification code the recipient enters in the application.
+	Code string
+	// Name is the optional name of the recipient used in the greeting.
+	Name string
+	// ExpiresIn is how long the link or code is valid. If zero, no expiry notice is added.
+	ExpiresIn time.Duration
+	// Subject is the subject of the email. Default is "Verify Your Email Address".
+	Subject string
+	// Intro is the line displayed before the verification button or code.
+	Intro string
+	// ActionText is the text of the verification button. Default is "Verify Email Address".
+	ActionText string
+	// ExpiryText is the expiry notice displayed after the verification button or code.
+	// Default is generated from ExpiresIn, e.g. "This verification code will expire in 10 minutes.".
+	ExpiryText string
+	// Outro is the line displayed at the end, advising the recipient what to do if they did not sign up.
+	Outro string
+}
+
+// EmailVerification creates a new Builder pre-populated with a standard email verification email:
+// an intro line, a verification button and/or code, an optional expiry notice, and a closing note.
+// The code is displayed in a dashed box like a Coupon.
+//
+// If neither VerifyURL nor Code is set, there is nothing to verify, so the default intro line and expiry
+// notice are not added either: the email only has the subject, the greeting, the Intro and ExpiryText if
+// set, and the closing note, and the verification content can be added to the returned Builder.
+//
+// The returned Builder can be customized further like any other Builder.
+//
+// Example usage:
+//
+//	message, err := mailgen.EmailVerification(mailgen.VerificationConfig{
+//		Code:      "482913",
+//		ExpiresIn: 10 * time.Minute,
+//	}).
+//		To("john@example.com").
+//		Build()
+func EmailVerification(cfg VerificationConfig) *Builder {
+	var kind, intro string
+	switch {
+	case cfg.Code != "":
+		kind = "code"
+		intro = "Please use the code below to verify your email address."
+	case cfg.VerifyURL != "":
+		kind = "link"
+		intro = "Please click the button below to verify your email address."
+	}
+
+	b := New().
+		Subject(stringOr(cfg.Subject, "Verify Your Email Address")).
+		Name(cfg.Name)
+	if intro = stringOr(cfg.Intro, intro); intro != "" {
+		b.Line(intro)
+	}
+	if cfg.Code != "" {
+		b.Coupon(cfg.Code)
+	}
+	if cfg.VerifyURL != "" {
+		b.Action(stringOr(cfg.ActionText, "Verify Email Address"), cfg.VerifyURL)
+	}
+
+	expiryText := cfg.ExpiryText
+	if expiryText == "" && cfg.ExpiresIn > 0 && kind != "" {
+		expiryText = fmt.Sprintf("This verification %s will expire in %s.", kind, formatDuration(cfg.ExpiresIn))
+	}
+	if expiryText != "" {
+		b.Line(expiryText)
+	}
+
+	return b.Outro(stringOr(cfg.Outro, "If you did not create an account, no further action is required."))
+}
+
+func stringOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// formatDuration formats d in the largest whole unit of days, hours, or minutes, e.g. "2 hours".
+func formatDuration(d time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case d >= day && d%day == 0:
+		return pluralize(int(d/day), "day")
+	case d >= time.Hour && d%time.Hour == 0:
+		return pluralize(int(d/time.Hour), "hour")
+	default:
+		return pluralize(max(int(d/time.Minute), 1), "minute")
+	}
+}
+
+func pluralize(count int, unit string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, unit)
+	}
+	return fmt.Sprintf("%d %ss", count, unit)
+}
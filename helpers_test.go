@@ -0,0 +1,191 @@
+package mailgen_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akfaiz/go-mailgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordReset(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "default password reset",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.PasswordReset(mailgen.PasswordResetConfig{
+					ResetURL:  "https://example.com/reset?token=abc",
+					Name:      "John Doe",
+					ExpiresIn: time.Hour,
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, "Reset Your Password", msg.Subject())
+				text := msg.PlainText()
+				assert.Contains(t, text, "Hi John Doe,")
+				assert.Contains(t, text, "we received a password reset request for your account.")
+				assert.Contains(t, text, "Reset Password (https://example.com/reset?token=abc)")
+				assert.Contains(t, text, "This password reset link will expire in 1 hour.")
+				assert.Contains(t, text, "If you did not request a password reset, no further action is required.")
+				assert.Less(
+					t,
+					strings.Index(text, "Reset Password ("),
+					strings.Index(text, "will expire in"),
+					"Expiry notice should follow the reset button",
+				)
+				assert.Contains(t, msg.HTML(), `href="https://example.com/reset?token=abc"`)
+				assert.Contains(t, msg.HTML(), `clicking the &#34;Reset Password&#34; button`)
+			},
+		},
+		{
+			name: "overridden texts",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.PasswordReset(mailgen.PasswordResetConfig{
+					ResetURL:   "https://example.com/reset",
+					ExpiresIn:  30 * time.Minute,
+					Subject:    "Password help",
+					Intro:      "Forgot your password?",
+					ActionText: "Choose a new password",
+					ExpiryText: "Hurry, this link is short-lived.",
+					Outro:      "Didn't ask for this? Ignore this email.",
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, "Password help", msg.Subject())
+				text := msg.PlainText()
+				assert.Contains(t, text, "Forgot your password?")
+				assert.Contains(t, text, "Choose a new password (https://example.com/reset)")
+				assert.Contains(t, text, "Hurry, this link is short-lived.")
+				assert.Contains(t, text, "Didn't ask for this? Ignore this email.")
+				assert.NotContains(t, text, "will expire in")
+			},
+		},
+		{
+			name: "no expiry notice without expiry",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.PasswordReset(mailgen.PasswordResetConfig{ResetURL: "https://example.com/reset"})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.NotContains(t, msg.PlainText(), "will expire in")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
+
+func TestPasswordReset_ExpiryFormat(t *testing.T) {
+	tests := []struct {
+		expiresIn time.Duration
+		expected  string
+	}{
+		{expiresIn: 45 * time.Minute, expected: "expire in 45 minutes."},
+		{expiresIn: 90 * time.Minute, expected: "expire in 90 minutes."},
+		{expiresIn: 2 * time.Hour, expected: "expire in 2 hours."},
+		{expiresIn: 24 * time.Hour, expected: "expire in 1 day."},
+		{expiresIn: 72 * time.Hour, expected: "expire in 3 days."},
+		{expiresIn: 10 * time.Second, expected: "expire in 1 minute."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expiresIn.String(), func(t *testing.T) {
+			msg, err := mailgen.PasswordReset(mailgen.PasswordResetConfig{
+				ResetURL:  "https://example.com/reset",
+				ExpiresIn: tt.expiresIn,
+			}).Build()
+			require.NoError(t, err)
+			assert.Contains(t, msg.PlainText(), tt.expected)
+		})
+	}
+}
+
+func TestEmailVerification(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "link verification",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.EmailVerification(mailgen.VerificationConfig{
+					VerifyURL: "https://example.com/verify?token=abc",
+					Name:      "Jane",
+					ExpiresIn: 24 * time.Hour,
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, "Verify Your Email Address", msg.Subject())
+				text := msg.PlainText()
+				assert.Contains(t, text, "Hi Jane,")
+				assert.Contains(t, text, "Please click the button below to verify your email address.")
+				assert.Contains(t, text, "Verify Email Address (https://example.com/verify?token=abc)")
+				assert.Contains(t, text, "This verification link will expire in 1 day.")
+				assert.Contains(t, text, "If you did not create an account, no further action is required.")
+				assert.NotContains(t, msg.HTML(), `class="discount"`, "Link verification should not render a code")
+			},
+		},
+		{
+			name: "code verification",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.EmailVerification(mailgen.VerificationConfig{
+					Code:      "482913",
+					ExpiresIn: 10 * time.Minute,
+				})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				text := msg.PlainText()
+				assert.Contains(t, text, "Please use the code below to verify your email address.")
+				assert.Contains(t, text, "[ 482913 ]")
+				assert.Contains(t, text, "This verification code will expire in 10 minutes.")
+				assert.Contains(t, msg.HTML(), ">482913</span>")
+				assert.NotContains(t, msg.HTML(), `class="body-action"`, "Code verification should not render a button")
+			},
+		},
+		{
+			name: "overridden texts",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.EmailVerification(mailgen.VerificationConfig{
+					VerifyURL:  "https://example.com/verify",
+					Subject:    "Confirm your account",
+					Intro:      "Almost there!",
+					ActionText: "Confirm",
+					ExpiryText: "Link valid for one use only.",
+					Outro:      "Welcome aboard.",
+				}).Line("Extra line")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, "Confirm your account", msg.Subject())
+				text := msg.PlainText()
+				assert.Contains(t, text, "Almost there!")
+				assert.Contains(t, text, "Confirm (https://example.com/verify)")
+				assert.Contains(t, text, "Link valid for one use only.")
+				assert.Contains(t, text, "Welcome aboard.\n\nExtra line")
+			},
+		},
+		{
+			name: "nothing to verify",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.EmailVerification(mailgen.VerificationConfig{ExpiresIn: time.Hour})
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Equal(t, "Verify Your Email Address", msg.Subject())
+				text := msg.PlainText()
+				assert.NotContains(t, text, "Please", "No intro should refer to a missing button or code")
+				assert.NotContains(t, text, "will expire")
+				assert.NotContains(t, msg.HTML(), `class="body-action"`)
+				assert.NotContains(t, msg.HTML(), `class="discount"`)
+				assert.Contains(t, text, "If you did not create an account, no further action is required.")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+}
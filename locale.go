@@ -0,0 +1,96 @@
+package mailgen
+
+import (
+	"strings"
+	"sync"
+)
+
+// Locale defines the localized default texts of an email for a language.
+type Locale struct {
+	// Greeting is the default greeting, e.g. "Hi".
+	Greeting string
+	// Salutation is the default salutation, e.g. "Best regards".
+	Salutation string
+	// FallbackFormat is the default fallback format of action buttons, see Builder.FallbackFormat.
+	FallbackFormat string
+}
+
+var (
+	localeRegistryMu sync.RWMutex
+	localeRegistry   = map[string]Locale{
+		"en": {
+			Greeting:   "Hi",
+			Salutation: "Best regards",
+			FallbackFormat: "If you're having trouble clicking the \"[ACTION]\" button, " +
+				"copy and paste the URL below into your web browser:",
+		},
+		"es": {
+			Greeting:   "Hola",
+			Salutation: "Saludos cordiales",
+			FallbackFormat: "Si tienes problemas para hacer clic en el botón \"[ACTION]\", " +
+				"copia y pega la siguiente URL en tu navegador web:",
+		},
+		"de": {
+			Greeting:   "Hallo",
+			Salutation: "Mit freundlichen Grüßen",
+			FallbackFormat: "Falls die Schaltfläche \"[ACTION]\" nicht funktioniert, " +
+				"kopieren Sie die folgende URL in Ihren Webbrowser:",
+		},
+		"fr": {
+			Greeting:   "Bonjour",
+			Salutation: "Cordialement",
+			FallbackFormat: "Si vous avez des difficultés à cliquer sur le bouton \"[ACTION]\", " +
+				"copiez et collez l'URL ci-dessous dans votre navigateur web :",
+		},
+	}
+)
+
+// RegisterLocale registers the localized defaults for a language that can be selected via Builder.Locale(lang),
+// or replaces those of a built-in language. Lang is case-insensitive, e.g. "it" or "pt-BR".
+// Empty fields of the locale fall back to English.
+//
+// Returns ErrInvalidLocale if lang is empty.
+func RegisterLocale(lang string, locale Locale) error {
+	lang = normalizeLocale(lang)
+	if lang == "" {
+		return ErrInvalidLocale
+	}
+	english := resolveLocale("en")
+	locale.Greeting = stringOr(locale.Greeting, english.Greeting)
+	locale.Salutation = stringOr(locale.Salutation, english.Salutation)
+	locale.FallbackFormat = stringOr(locale.FallbackFormat, english.FallbackFormat)
+
+	localeRegistryMu.Lock()
+	localeRegistry[lang] = locale
+	localeRegistryMu.Unlock()
+
+	return nil
+}
+
+// resolveLocale returns the locale registered for lang, then for its base language,
+// e.g. "es" for "es-MX", and falls back to English.
+func resolveLocale(lang string) Locale {
+	locale, _ := lookupLocale(lang)
+	return locale
+}
+
+// lookupLocale returns the locale registered for lang, then for its base language, and whether
+// one of them is registered. If neither is, it returns the English locale.
+func lookupLocale(lang string) (Locale, bool) {
+	lang = normalizeLocale(lang)
+	localeRegistryMu.RLock()
+	defer localeRegistryMu.RUnlock()
+	if locale, ok := localeRegistry[lang]; ok {
+		return locale, true
+	}
+	if base, _, found := strings.Cut(lang, "-"); found {
+		if locale, ok := localeRegistry[base]; ok {
+			return locale, true
+		}
+	}
+	return localeRegistry["en"], false
+}
+
+func normalizeLocale(lang string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(lang)), "_", "-")
+}
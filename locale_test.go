@@ -0,0 +1,109 @@
+package mailgen_test
+
+import (
+	"testing"
+
+	"github.com/akfaiz/go-mailgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_Locale(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "spanish locale",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Locale("es").Name("Juan").Action("Confirmar", "https://example.com/confirm")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "Hola Juan,")
+				assert.Contains(t, msg.PlainText(), "Saludos cordiales,")
+				assert.Contains(t, msg.HTML(), "Si tienes problemas para hacer clic en el botón")
+			},
+		},
+		{
+			name: "regional german locale falls back to the base language",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Locale("de_AT")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "Hallo,")
+				assert.Contains(t, msg.PlainText(), "Mit freundlichen Grüßen,")
+			},
+		},
+		{
+			name: "explicit greeting and salutation override the locale",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Locale("FR").Greeting("Salut").Salutation("À bientôt")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "Salut,")
+				assert.Contains(t, msg.PlainText(), "À bientôt,")
+				assert.NotContains(t, msg.PlainText(), "Bonjour")
+			},
+		},
+		{
+			name: "greeting and salutation set before the locale are kept",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().
+					Greeting("Salut").
+					Salutation("À bientôt").
+					Locale("de").
+					Action("Bestätigen", "https://example.com/confirm")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "Salut,")
+				assert.Contains(t, msg.PlainText(), "À bientôt,")
+				assert.NotContains(t, msg.PlainText(), "Hallo")
+				assert.Contains(t, msg.HTML(), "Falls die Schaltfläche", "The fallback format should still be localized")
+			},
+		},
+		{
+			name: "unknown locale falls back to english",
+			builderFunc: func() *mailgen.Builder {
+				return mailgen.New().Locale("xx")
+			},
+			expectError: false,
+			expectFunc: func(msg mailgen.Message) {
+				assert.Contains(t, msg.PlainText(), "Hi,")
+				assert.Contains(t, msg.PlainText(), "Best regards,")
+				assert.NotContains(t, msg.HTML(), `lang=`, "Unknown languages should not be set")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.run(t)
+	}
+
+	t.Run("greeting set on the default builder is kept", func(t *testing.T) {
+		originalDefault := mailgen.New()
+		defer mailgen.SetDefault(originalDefault)
+		mailgen.SetDefault(mailgen.New().Greeting("Hey"))
+
+		msg, err := mailgen.New().Locale("es").Build()
+		require.NoError(t, err)
+		assert.Contains(t, msg.PlainText(), "Hey,")
+		assert.Contains(t, msg.PlainText(), "Saludos cordiales,")
+	})
+}
+
+func TestRegisterLocale(t *testing.T) {
+	require.ErrorIs(t, mailgen.RegisterLocale(" ", mailgen.Locale{Greeting: "Ciao"}), mailgen.ErrInvalidLocale)
+
+	require.NoError(t, mailgen.RegisterLocale("IT", mailgen.Locale{Greeting: "Ciao", Salutation: "Cordiali saluti"}))
+
+	msg, err := mailgen.New().Locale("it").Action("Conferma", "https://example.com/confirm").Build()
+	require.NoError(t, err)
+	assert.Contains(t, msg.PlainText(), "Ciao,")
+	assert.Contains(t, msg.PlainText(), "Cordiali saluti,")
+	assert.Contains(
+		t,
+		msg.HTML(),
+		"If you&#39;re having trouble clicking the",
+		"Missing fields should fall back to english",
+	)
+}
@@ -0,0 +1,67 @@
+package mailgen
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	markdownLinkRegex   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	markdownBoldRegex   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	markdownItalicRegex = regexp.MustCompile(`\*([^*]+)\*|(^|[^\w])_([^_]+)_([^\w]|$)`)
+	markdownTokenRegex  = regexp.MustCompile("\x00([0-9]+)\x00")
+)
+
+// markdownToHTML converts the inline markdown elements of text (bold, italic, and links) to HTML.
+// The text is HTML-escaped first, and links are only rendered for http, https, and mailto URLs.
+// NUL characters are removed, as they delimit the link placeholders.
+func markdownToHTML(text string) string {
+	escaped := html.EscapeString(strings.ReplaceAll(text, "\x00", ""))
+
+	// Replace links with placeholders, so that their URLs are not affected by emphasis.
+	var links []string
+	escaped = markdownLinkRegex.ReplaceAllStringFunc(escaped, func(match string) string {
+		parts := markdownLinkRegex.FindStringSubmatch(match)
+		link := parts[1]
+		if isSafeMarkdownURL(html.UnescapeString(parts[2])) {
+			link = fmt.Sprintf(`<a href="%s" target="_blank">%s</a>`, parts[2], parts[1])
+		}
+		links = append(links, link)
+		return "\x00" + strconv.Itoa(len(links)-1) + "\x00"
+	})
+
+	escaped = markdownBoldRegex.ReplaceAllString(escaped, "<strong>$1</strong>")
+	escaped = markdownItalicRegex.ReplaceAllStringFunc(escaped, func(match string) string {
+		parts := markdownItalicRegex.FindStringSubmatch(match)
+		if parts[1] != "" {
+			return "<em>" + parts[1] + "</em>"
+		}
+		return parts[2] + "<em>" + parts[3] + "</em>" + parts[4]
+	})
+
+	return markdownTokenRegex.ReplaceAllStringFunc(escaped, func(match string) string {
+		index, _ := strconv.Atoi(markdownTokenRegex.FindStringSubmatch(match)[1])
+		return links[index]
+	})
+}
+
+// markdownToText strips the inline markdown elements of text for plain text output.
+// Links are rendered as "text (url)".
+func markdownToText(text string) string {
+	text = markdownLinkRegex.ReplaceAllString(text, "$1 ($2)")
+	text = markdownBoldRegex.ReplaceAllString(text, "$1")
+	return markdownItalicRegex.ReplaceAllString(text, "$1$2$3$4")
+}
+
+func isSafeMarkdownURL(url string) bool {
+	url = strings.ToLower(strings.TrimSpace(url))
+	for _, scheme := range []string{"http://", "https://", "mailto:"} {
+		if strings.HasPrefix(url, scheme) {
+			return true
+		}
+	}
+	return false
+}
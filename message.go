@@ -1,5 +1,7 @@
 package mailgen
 
+import "strings"
+
 // Message represents an email message with its components.
 type Message interface {
 	// Subject returns the subject of the email.
@@ -12,42 +14,75 @@ type Message interface {
 	ReplyTo() *Address
 	// ReplyToString returns the Reply-To address as a formatted string.
 	ReplyToString() string
+	// Sender returns the Sender address, or nil if it is not set via Builder.Sender.
+	Sender() *Address
+	// SenderString returns the Sender address as a formatted string.
+	SenderString() string
 	// To returns the list of recipient addresses.
 	To() []string
 	// Cc returns the list of CC addresses.
 	Cc() []string
 	// Bcc returns the list of BCC addresses.
 	Bcc() []string
+	// Headers returns the custom headers of the email, keyed by canonical header name.
+	Headers() map[string][]string
+	// Priority returns the priority of the email, set via Builder.Priority.
+	Priority() Priority
+	// ContentIDs returns the Content-IDs referenced by inline images, e.g. "logo" for src="cid:logo".
+	// Each must match the Content-ID of an image attached to the email by the mailer.
+	ContentIDs() []string
 	// HTML returns the HTML content of the email.
 	HTML() string
 	// PlainText returns the plain text content of the email.
 	PlainText() string
+	// SizeHTML returns the size of the HTML content in bytes, e.g. to check it against the size
+	// at which Gmail clips messages, see Builder.WarnOnClipping.
+	SizeHTML() int
+	// SizeText returns the size of the plain text content in bytes.
+	SizeText() int
+	// AMPHTML returns the AMP for Email content of the email, or "" if Builder.AMP is not enabled.
+	// It is sent as the text/x-amp-html part of the email.
+	AMPHTML() string
 }
 
 // Address represents an email address with an optional name.
 type Address struct {
-	Name    string
-	Address string
+	Name    string `json:"name,omitempty"`
+	Address string `json:"address,omitempty"`
 }
 
+// String returns the address formatted for the From or Reply-To header, e.g. "John Doe <john@example.com>".
+// Names containing special characters, e.g. "Doe, John", are quoted so that the result parses as a single address.
 func (a Address) String() string {
 	if a.Name == "" {
 		return a.Address
 	}
-	return a.Name + " <" + a.Address + ">"
+	name := a.Name
+	if strings.ContainsAny(name, addressSpecials) {
+		name = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name) + `"`
+	}
+	return name + " <" + a.Address + ">"
 }
 
+// addressSpecials are the characters that must be quoted in the display name of an address (RFC 5322).
+const addressSpecials = `()<>[]:;@\,."`
+
 var _ Message = (*message)(nil)
 
 type message struct {
 	subject   string
 	from      Address
 	replyTo   *Address
+	sender    *Address
 	to        []string
 	cc        []string
 	bcc       []string
+	headers   map[string][]string
+	cids      []string
+	priority  Priority
 	html      string
 	plainText string
+	ampHTML   string
 }
 
 func (m *message) Subject() string {
@@ -73,6 +108,17 @@ func (m *message) ReplyToString() string {
 	return m.replyTo.String()
 }
 
+func (m *message) Sender() *Address {
+	return m.sender
+}
+
+func (m *message) SenderString() string {
+	if m.sender == nil {
+		return ""
+	}
+	return m.sender.String()
+}
+
 func (m *message) To() []string {
 	return m.to
 }
@@ -85,6 +131,18 @@ func (m *message) Bcc() []string {
 	return m.bcc
 }
 
+func (m *message) Headers() map[string][]string {
+	return m.headers
+}
+
+func (m *message) Priority() Priority {
+	return m.priority
+}
+
+func (m *message) ContentIDs() []string {
+	return m.cids
+}
+
 func (m *message) HTML() string {
 	return m.html
 }
@@ -92,3 +150,15 @@ func (m *message) HTML() string {
 func (m *message) PlainText() string {
 	return m.plainText
 }
+
+func (m *message) SizeHTML() int {
+	return len(m.html)
+}
+
+func (m *message) SizeText() int {
+	return len(m.plainText)
+}
+
+func (m *message) AMPHTML() string {
+	return m.ampHTML
+}
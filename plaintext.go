@@ -0,0 +1,32 @@
+package mailgen
+
+import (
+	"strings"
+
+	"github.com/inbucket/html2text"
+)
+
+// HTMLToText converts an HTML document or fragment to plain text.
+//
+// The head of the document, including styles, is skipped, links are rendered as "text ( url )",
+// and headings are emphasized. It is used by Builder.AutoPlainText and can be used on its own
+// to derive the plain text part of an HTML-first email.
+func HTMLToText(html string) (string, error) {
+	text, err := htmlToText(html)
+	if err != nil {
+		return "", err
+	}
+	return cleanEmailText(text, NewlineCompact), nil
+}
+
+func htmlToText(html string) (string, error) {
+	text, err := html2text.FromString(html)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Join(lines, "\n"), nil
+}
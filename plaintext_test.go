@@ -0,0 +1,42 @@
+package mailgen_test
+
+import (
+	"testing"
+
+	"github.com/akfaiz/go-mailgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "paragraphs",
+			html:     "<p>Hello <b>world</b></p><p>Second paragraph</p>",
+			expected: "Hello *world*\n\nSecond paragraph",
+		},
+		{
+			name:     "links",
+			html:     `<p>Visit <a href="https://example.com">our site</a></p>`,
+			expected: "Visit our site ( https://example.com )",
+		},
+		{
+			name: "document with head and styles",
+			html: `<html><head><title>Title</title><style>p { color: red; }</style></head>` +
+				`<body><h1>Welcome</h1><p>Body text</p></body></html>`,
+			expected: "*******\nWelcome\n*******\n\nBody text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := mailgen.HTMLToText(tt.html)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
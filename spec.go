@@ -0,0 +1,630 @@
+package mailgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Component types used in the "type" field of a ComponentSpec.
+const (
+	ComponentLine        = "line"
+	ComponentAction      = "action"
+	ComponentActionGroup = "actionGroup"
+	ComponentTable       = "table"
+	ComponentCallout     = "callout"
+	ComponentImage       = "image"
+	ComponentDivider     = "divider"
+	ComponentCoupon      = "coupon"
+	ComponentList        = "list"
+	ComponentShipping    = "shipping"
+	ComponentRawHTML     = "rawHTML"
+	ComponentDigestItem  = "digestItem"
+	ComponentTitle       = "title"
+	ComponentSection     = "section"
+	ComponentAlert       = "alert"
+)
+
+// BuilderSpec is a declarative, JSON-serializable description of a Builder.
+// It allows emails to be stored as JSON, e.g. in a CMS, and turned into a Builder at runtime.
+//
+// It describes all the configuration of a Builder except the options that cannot be serialized:
+// the RewriteLinks function and RewriteAllLinks, the Logger, the Clock, and the PremailerOptions.
+//
+// Example JSON:
+//
+//	{
+//		"subject": "Welcome",
+//		"to": ["john@example.com"],
+//		"components": [
+//			{"type": "line", "text": "Welcome to Go-Mailgen!"},
+//			{"type": "action", "text": "Get Started", "link": "https://example.com"}
+//		]
+//	}
+type BuilderSpec struct {
+	Subject       string          `json:"subject,omitempty"`
+	From          *Address        `json:"from,omitempty"`
+	ReplyTo       *Address        `json:"replyTo,omitempty"`
+	Sender        *Address        `json:"sender,omitempty"`
+	To            []string        `json:"to,omitempty"`
+	Cc            []string        `json:"cc,omitempty"`
+	Bcc           []string        `json:"bcc,omitempty"`
+	Preheader     string          `json:"preheader,omitempty"`
+	Greeting      string          `json:"greeting,omitempty"`
+	Name          string          `json:"name,omitempty"`
+	Salutation    string          `json:"salutation,omitempty"`
+	Signature     *Signature      `json:"signature,omitempty"`
+	Product       *Product        `json:"product,omitempty"`
+	Social        []SocialLink    `json:"social,omitempty"`
+	Theme         string          `json:"theme,omitempty"`
+	TextDirection string          `json:"textDirection,omitempty"`
+	Language      string          `json:"language,omitempty"`
+	Components    []ComponentSpec `json:"components,omitempty"`
+
+	Headers          map[string][]string   `json:"headers,omitempty"`
+	MessageID        string                `json:"messageID,omitempty"`
+	MessageIDDomain  string                `json:"messageIDDomain,omitempty"`
+	Priority         string                `json:"priority,omitempty"` // "low" or "high"
+	Unsubscribe      *UnsubscribeSpec      `json:"unsubscribe,omitempty"`
+	PreferenceCenter *PreferenceCenterSpec `json:"preferenceCenter,omitempty"`
+	ViewInBrowser    *ViewInBrowserSpec    `json:"viewInBrowser,omitempty"`
+
+	Doctype         string  `json:"doctype,omitempty"`
+	AMP             bool    `json:"amp,omitempty"`
+	BrandColor      string  `json:"brandColor,omitempty"`
+	Font            string  `json:"font,omitempty"`
+	BodyColor       string  `json:"bodyColor,omitempty"`
+	BackgroundColor string  `json:"backgroundColor,omitempty"`
+	DarkMode        *bool   `json:"darkMode,omitempty"`
+	InlineCSS       *bool   `json:"inlineCSS,omitempty"`
+	GreetingFormat  string  `json:"greetingFormat,omitempty"`
+	NoGreeting      bool    `json:"noGreeting,omitempty"`
+	NoSalutation    bool    `json:"noSalutation,omitempty"`
+	Bare            bool    `json:"bare,omitempty"`
+	CopyrightSuffix *string `json:"copyrightSuffix,omitempty"`
+
+	FallbackFormat    string `json:"fallbackFormat,omitempty"`
+	FallbackPlacement string `json:"fallbackPlacement,omitempty"`
+	AutoPlainText     bool   `json:"autoPlainText,omitempty"`
+	PlainTextHeader   *bool  `json:"plainTextHeader,omitempty"`
+	NewlineMode       string `json:"newlineMode,omitempty"`
+	WrapColumns       int    `json:"wrapColumns,omitempty"`
+
+	StrictAddresses          bool              `json:"strictAddresses,omitempty"`
+	ValidateOnBuild          bool              `json:"validateOnBuild,omitempty"`
+	AllowDuplicateRecipients bool              `json:"allowDuplicateRecipients,omitempty"`
+	StrictLinks              bool              `json:"strictLinks,omitempty"`
+	AllowedLinkSchemes       []string          `json:"allowedLinkSchemes,omitempty"`
+	LinkParams               map[string]string `json:"linkParams,omitempty"`
+	OverrideLinkParams       bool              `json:"overrideLinkParams,omitempty"`
+	WarnOnClipping           bool              `json:"warnOnClipping,omitempty"`
+	ClipThreshold            int               `json:"clipThreshold,omitempty"`
+}
+
+// UnsubscribeSpec describes the unsubscribe link set via Builder.Unsubscribe.
+type UnsubscribeSpec struct {
+	URL      string `json:"url"`
+	Mailto   string `json:"mailto,omitempty"`
+	ShowLink bool   `json:"showLink,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
+
+// PreferenceCenterSpec describes the preference center link set via Builder.PreferenceCenter.
+type PreferenceCenterSpec struct {
+	URL    string `json:"url"`
+	Text   string `json:"text,omitempty"`
+	Header bool   `json:"header,omitempty"`
+}
+
+// ViewInBrowserSpec describes the "view in browser" link set via Builder.ViewInBrowser.
+type ViewInBrowserSpec struct {
+	URL  string `json:"url"`
+	Text string `json:"text,omitempty"`
+}
+
+// ComponentSpec wraps a Component for JSON serialization.
+// It is encoded as the fields of the component plus a "type" field, e.g. {"type": "line", "text": "Hello"},
+// which is used to decode it back into the correct Component.
+type ComponentSpec struct {
+	Component Component
+}
+
+// ToSpec returns the declarative description of the Builder.
+//
+// Only the built-in components are supported; the spec of any other Component fails to marshal.
+func (b *Builder) ToSpec() BuilderSpec {
+	spec := BuilderSpec{
+		Subject:       b.subject,
+		To:            append([]string{}, b.to...),
+		Cc:            append([]string{}, b.cc...),
+		Bcc:           append([]string{}, b.bcc...),
+		Preheader:     b.preheader,
+		Greeting:      b.greeting,
+		Name:          b.name,
+		Salutation:    b.salutation,
+		Theme:         b.theme,
+		TextDirection: b.textDirection,
+		Language:      b.language,
+	}
+	if b.from != (Address{}) {
+		from := b.from
+		spec.From = &from
+	}
+	if b.replyTo != nil {
+		replyTo := *b.replyTo
+		spec.ReplyTo = &replyTo
+	}
+	if b.sender != nil {
+		sender := *b.sender
+		spec.Sender = &sender
+	}
+	if b.signature != nil {
+		signature := *b.signature
+		spec.Signature = &signature
+	}
+	product := b.product
+	spec.Product = &product
+	spec.Social = append([]SocialLink{}, b.social...)
+	for _, component := range b.components {
+		spec.Components = append(spec.Components, ComponentSpec{Component: component})
+	}
+	b.headerSpec(&spec)
+	b.appearanceSpec(&spec)
+	b.optionsSpec(&spec)
+	return spec
+}
+
+// headerSpec sets the fields of spec describing the headers and footer links of the email.
+func (b *Builder) headerSpec(spec *BuilderSpec) {
+	if len(b.headers) > 0 {
+		spec.Headers = make(map[string][]string, len(b.headers))
+		for key, values := range b.headers {
+			spec.Headers[key] = append([]string{}, values...)
+		}
+	}
+	spec.MessageID = b.messageID
+	spec.MessageIDDomain = b.messageIDDomain
+	if b.priority != PriorityNormal {
+		spec.Priority = b.priority.String()
+	}
+	if b.unsubscribeURL != "" {
+		spec.Unsubscribe = &UnsubscribeSpec{
+			URL:      b.unsubscribeURL,
+			Mailto:   b.unsubscribe.Mailto,
+			ShowLink: b.unsubscribe.ShowLink,
+			Text:     b.unsubscribe.Text,
+		}
+	}
+	if b.preferenceURL != "" {
+		spec.PreferenceCenter = &PreferenceCenterSpec{
+			URL:    b.preferenceURL,
+			Text:   b.preferenceText,
+			Header: b.preference.Header,
+		}
+	}
+	if b.browserURL != "" {
+		spec.ViewInBrowser = &ViewInBrowserSpec{URL: b.browserURL, Text: b.browserText}
+	}
+}
+
+// appearanceSpec sets the fields of spec describing how the email is rendered.
+func (b *Builder) appearanceSpec(spec *BuilderSpec) {
+	darkMode, inlineCSS, plainTextHeader := !b.noDarkMode, b.usePremailer, !b.noTextHeader
+	copyrightSuffix := b.copyrightSuffix
+	spec.Doctype = b.doctype
+	spec.AMP = b.amp
+	spec.BrandColor = b.brandColor
+	spec.Font = b.fontFamily
+	spec.BodyColor = b.bodyColor
+	spec.BackgroundColor = b.backgroundColor
+	spec.DarkMode = &darkMode
+	spec.InlineCSS = &inlineCSS
+	spec.GreetingFormat = b.greetingFormat
+	spec.NoGreeting = b.noGreeting
+	spec.NoSalutation = b.noSalutation
+	spec.Bare = b.bare
+	spec.CopyrightSuffix = &copyrightSuffix
+	spec.FallbackFormat = b.fallbackFormat
+	spec.FallbackPlacement = b.fallbackPlacement
+	spec.AutoPlainText = b.autoPlainText
+	spec.PlainTextHeader = &plainTextHeader
+	spec.NewlineMode = b.newlineMode
+	spec.WrapColumns = b.wrapColumns
+}
+
+// optionsSpec sets the fields of spec describing how the email is validated and its links are tagged.
+func (b *Builder) optionsSpec(spec *BuilderSpec) {
+	spec.StrictAddresses = b.strictAddress
+	spec.ValidateOnBuild = b.validateOnBuild
+	spec.AllowDuplicateRecipients = b.allowDuplicates
+	spec.StrictLinks = b.strictLinks
+	spec.AllowedLinkSchemes = append([]string{}, b.linkSchemes...)
+	if len(b.linkParams) > 0 {
+		spec.LinkParams = make(map[string]string, len(b.linkParams))
+		for key, value := range b.linkParams {
+			spec.LinkParams[key] = value
+		}
+	}
+	spec.OverrideLinkParams = b.overrideParams
+	spec.WarnOnClipping = b.warnOnClipping
+	spec.ClipThreshold = b.clipThreshold
+}
+
+// FromSpec creates a new Builder from the declarative description.
+// The Builder starts from the defaults set via SetDefault, and empty fields of the spec keep the defaults.
+//
+// Example usage:
+//
+//	var spec mailgen.BuilderSpec
+//	if err := json.Unmarshal(data, &spec); err != nil {
+//		return err
+//	}
+//	message, err := mailgen.FromSpec(spec).Build()
+func FromSpec(spec BuilderSpec) *Builder {
+	b := New()
+	if spec.Subject != "" {
+		b.Subject(spec.Subject)
+	}
+	if spec.From != nil {
+		b.From(spec.From.Address, spec.From.Name)
+	}
+	if spec.ReplyTo != nil {
+		b.ReplyTo(spec.ReplyTo.Address, spec.ReplyTo.Name)
+	}
+	if spec.Sender != nil {
+		b.Sender(spec.Sender.Address, spec.Sender.Name)
+	}
+	if len(spec.To) > 0 {
+		b.To(spec.To[0], spec.To[1:]...)
+	}
+	if len(spec.Cc) > 0 {
+		b.Cc(spec.Cc[0], spec.Cc[1:]...)
+	}
+	if len(spec.Bcc) > 0 {
+		b.Bcc(spec.Bcc[0], spec.Bcc[1:]...)
+	}
+	if spec.Preheader != "" {
+		b.Preheader(spec.Preheader)
+	}
+	if spec.Greeting != "" {
+		b.Greeting(spec.Greeting)
+	}
+	if spec.Name != "" {
+		b.Name(spec.Name)
+	}
+	if spec.Salutation != "" {
+		b.Salutation(spec.Salutation)
+	}
+	if spec.Signature != nil {
+		b.Signature(*spec.Signature)
+	}
+	if spec.Product != nil {
+		b.Product(*spec.Product)
+	}
+	if len(spec.Social) > 0 {
+		b.Social(spec.Social)
+	}
+	if spec.Theme != "" {
+		b.Theme(spec.Theme)
+	}
+	if spec.TextDirection != "" {
+		b.TextDirection(spec.TextDirection)
+	}
+	if spec.Language != "" {
+		b.Language(spec.Language)
+	}
+	for _, cs := range spec.Components {
+		b.addComponent(cs.Component)
+	}
+	b.applyHeaderSpec(spec)
+	b.applyAppearanceSpec(spec)
+	b.applyOptionsSpec(spec)
+	return b
+}
+
+// applyHeaderSpec applies the fields of spec describing the headers and footer links of the email.
+func (b *Builder) applyHeaderSpec(spec BuilderSpec) {
+	if len(spec.Headers) > 0 {
+		b.headers = nil
+		keys := slices.Sorted(maps.Keys(spec.Headers))
+		for _, key := range keys {
+			for _, value := range spec.Headers[key] {
+				b.Header(key, value)
+			}
+		}
+	}
+	if spec.MessageID != "" {
+		b.MessageID(spec.MessageID)
+	}
+	if spec.MessageIDDomain != "" {
+		b.MessageIDDomain(spec.MessageIDDomain)
+	}
+	switch spec.Priority {
+	case PriorityLow.String():
+		b.Priority(PriorityLow)
+	case PriorityHigh.String():
+		b.Priority(PriorityHigh)
+	}
+	if u := spec.Unsubscribe; u != nil {
+		b.Unsubscribe(u.URL, UnsubscribeOption{Mailto: u.Mailto, ShowLink: u.ShowLink, Text: u.Text})
+	}
+	if p := spec.PreferenceCenter; p != nil {
+		b.PreferenceCenter(p.URL, p.Text, PreferenceCenterOption{Header: p.Header})
+	}
+	if v := spec.ViewInBrowser; v != nil {
+		b.ViewInBrowser(v.URL, v.Text)
+	}
+}
+
+// applyAppearanceSpec applies the fields of spec describing how the email is rendered.
+func (b *Builder) applyAppearanceSpec(spec BuilderSpec) {
+	if spec.Doctype != "" {
+		b.Doctype(spec.Doctype)
+	}
+	if spec.AMP {
+		b.AMP(true)
+	}
+	if spec.BrandColor != "" {
+		b.BrandColor(spec.BrandColor)
+	}
+	if spec.Font != "" {
+		b.Font(spec.Font)
+	}
+	if spec.BodyColor != "" || spec.BackgroundColor != "" {
+		b.Colors(spec.BodyColor, spec.BackgroundColor)
+	}
+	if spec.DarkMode != nil {
+		b.DarkMode(*spec.DarkMode)
+	}
+	if spec.InlineCSS != nil {
+		b.InlineCSS(*spec.InlineCSS)
+	}
+	if spec.GreetingFormat != "" {
+		b.GreetingFormat(spec.GreetingFormat)
+	}
+	if spec.NoGreeting {
+		b.NoGreeting()
+	}
+	if spec.NoSalutation {
+		b.NoSalutation()
+	}
+	if spec.Bare {
+		b.Bare()
+	}
+	if spec.CopyrightSuffix != nil {
+		b.CopyrightSuffix(*spec.CopyrightSuffix)
+	}
+	if spec.FallbackFormat != "" {
+		b.FallbackFormat(spec.FallbackFormat)
+	}
+	if spec.FallbackPlacement != "" {
+		b.FallbackPlacement(spec.FallbackPlacement)
+	}
+	if spec.AutoPlainText {
+		b.AutoPlainText()
+	}
+	if spec.PlainTextHeader != nil {
+		b.PlainTextHeader(*spec.PlainTextHeader)
+	}
+	if spec.NewlineMode != "" {
+		b.PlainTextNewlineMode(spec.NewlineMode)
+	}
+	if spec.WrapColumns > 0 {
+		b.WrapText(spec.WrapColumns)
+	}
+}
+
+// applyOptionsSpec applies the fields of spec describing how the email is validated and its links are tagged.
+func (b *Builder) applyOptionsSpec(spec BuilderSpec) {
+	if spec.StrictAddresses {
+		b.StrictAddresses(true)
+	}
+	if spec.ValidateOnBuild {
+		b.ValidateOnBuild(true)
+	}
+	if spec.AllowDuplicateRecipients {
+		b.AllowDuplicateRecipients(true)
+	}
+	if spec.StrictLinks {
+		b.StrictLinks(true)
+	}
+	if len(spec.AllowedLinkSchemes) > 0 {
+		b.AllowedLinkSchemes(spec.AllowedLinkSchemes...)
+	}
+	if len(spec.LinkParams) > 0 {
+		b.LinkParams(spec.LinkParams)
+	}
+	if spec.OverrideLinkParams {
+		b.OverrideLinkParams(true)
+	}
+	if spec.WarnOnClipping {
+		b.WarnOnClipping(true)
+	}
+	if spec.ClipThreshold > 0 {
+		b.ClipThreshold(spec.ClipThreshold)
+	}
+}
+
+// MarshalJSON encodes the Builder as its BuilderSpec. The options that BuilderSpec does not describe,
+// such as the RewriteLinks function and the Logger, are not encoded.
+func (b *Builder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.ToSpec())
+}
+
+// UnmarshalJSON decodes a BuilderSpec into the Builder, replacing its configuration
+// with the defaults set via SetDefault overridden by the spec.
+func (b *Builder) UnmarshalJSON(data []byte) error {
+	var spec BuilderSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	*b = *FromSpec(spec)
+	return nil
+}
+
+func (b *Builder) addComponent(component Component) {
+	switch c := component.(type) {
+	case *Action:
+		action := *c
+		b.components = append(b.components, &action)
+		if !action.NoFallback {
+			b.fallbacks = append(b.fallbacks, &action)
+		}
+	case *ActionGroup:
+		group := &ActionGroup{}
+		for _, a := range c.Actions {
+			action := *a
+			group.Actions = append(group.Actions, &action)
+			if !action.NoFallback {
+				b.fallbacks = append(b.fallbacks, &action)
+			}
+		}
+		b.components = append(b.components, group)
+	case *DigestItem:
+		item := &Builder{}
+		for _, nested := range c.Components {
+			item.addComponent(nested)
+		}
+		b.components = append(b.components, &DigestItem{Title: c.Title, Components: item.components})
+		b.fallbacks = append(b.fallbacks, item.fallbacks...)
+	case nil:
+		// Nothing to add
+	default:
+		b.components = append(b.components, component)
+	}
+}
+
+// MarshalJSON encodes the component with a "type" field identifying it.
+func (cs ComponentSpec) MarshalJSON() ([]byte, error) {
+	componentType, err := componentTypeOf(cs.Component)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	data, err := json.Marshal(cs.Component)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["type"], _ = json.Marshal(componentType)
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON decodes the component according to its "type" field.
+func (cs *ComponentSpec) UnmarshalJSON(data []byte) error {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return err
+	}
+	var component Component
+	switch header.Type {
+	case ComponentLine:
+		component = &Line{}
+	case ComponentAction:
+		component = &Action{}
+	case ComponentActionGroup:
+		component = &ActionGroup{}
+	case ComponentTable:
+		component = &Table{}
+	case ComponentCallout:
+		component = &Callout{}
+	case ComponentImage:
+		component = &Image{}
+	case ComponentDivider:
+		component = &Divider{}
+	case ComponentCoupon:
+		component = &Coupon{}
+	case ComponentList:
+		component = &List{}
+	case ComponentShipping:
+		component = &Shipping{}
+	case ComponentRawHTML:
+		component = &RawHTML{}
+	case ComponentDigestItem:
+		component = &DigestItem{}
+	case ComponentTitle:
+		component = &Title{}
+	case ComponentSection:
+		component = &Section{}
+	case ComponentAlert:
+		component = &Alert{}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownComponentType, header.Type)
+	}
+	if err := json.Unmarshal(data, component); err != nil {
+		return err
+	}
+	cs.Component = component
+	return nil
+}
+
+func componentTypeOf(component Component) (string, error) {
+	switch component.(type) {
+	case Line, *Line:
+		return ComponentLine, nil
+	case *Action:
+		return ComponentAction, nil
+	case *ActionGroup:
+		return ComponentActionGroup, nil
+	case *Table:
+		return ComponentTable, nil
+	case *Callout:
+		return ComponentCallout, nil
+	case *Image:
+		return ComponentImage, nil
+	case *Divider:
+		return ComponentDivider, nil
+	case *Coupon:
+		return ComponentCoupon, nil
+	case *List:
+		return ComponentList, nil
+	case *Shipping:
+		return ComponentShipping, nil
+	case *RawHTML:
+		return ComponentRawHTML, nil
+	case *DigestItem:
+		return ComponentDigestItem, nil
+	case *Title:
+		return ComponentTitle, nil
+	case *Section:
+		return ComponentSection, nil
+	case *Alert:
+		return ComponentAlert, nil
+	default:
+		return "", fmt.Errorf("%w: %T", ErrUnknownComponentType, component)
+	}
+}
+
+// digestItemSpec is the JSON representation of a DigestItem, with its components wrapped in ComponentSpecs.
+type digestItemSpec struct {
+	Title      string          `json:"title,omitempty"`
+	Components []ComponentSpec `json:"components,omitempty"`
+}
+
+// MarshalJSON encodes the digest item with a "type" field for each of its components.
+func (d DigestItem) MarshalJSON() ([]byte, error) {
+	spec := digestItemSpec{Title: d.Title}
+	for _, component := range d.Components {
+		spec.Components = append(spec.Components, ComponentSpec{Component: component})
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON decodes the digest item and its components according to their "type" field.
+func (d *DigestItem) UnmarshalJSON(data []byte) error {
+	var spec digestItemSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	d.Title = spec.Title
+	d.Components = nil
+	for _, cs := range spec.Components {
+		d.Components = append(d.Components, cs.Component)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package mailgen_test
+
+import (
+	"encoding/json"
+	htmltemplate "html/template"
+	"testing"
+
+	"github.com/akfaiz/go-mailgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_JSONRoundTrip(t *testing.T) {
+	original := mailgen.New().
+		Subject("Your order").
+		From("shop@example.com", "Shop").
+		Sender("notifications@example.com", "Notifications").
+		To("john@example.com", "jane@example.com").
+		Cc("sales@example.com").
+		Greeting("Hello").
+		Name("John").
+		Salutation("Cheers").
+		Signature(mailgen.Signature{Name: "Jane Doe", Title: "Account Manager"}).
+		Theme("plain").
+		TextDirection("rtl").
+		Language("ar").
+		Product(mailgen.Product{Name: "Shop", Link: "https://example.com"}).
+		Title("Order confirmed").
+		Alert(mailgen.AlertSuccess, "Payment received").
+		Line("Your order has been processed.").
+		Markdown("Questions? **Reply** to this email.").
+		Table(mailgen.Table{
+			Data: [][]mailgen.Entry{
+				{{Key: "Item", Value: "Golang"}, {Key: "Price", Value: "$10.99"}},
+			},
+			Columns: mailgen.Columns{CustomAlign: map[string]string{"Price": "right"}},
+		}).
+		Action("View Order", "https://example.com/orders/1").
+		Actions(
+			mailgen.Action{Text: "Track", Link: "https://example.com/track"},
+			mailgen.Action{Text: "Support", Link: "https://example.com/support", Style: "secondary", NoFallback: true},
+		).
+		Divider().
+		List([]string{"Fast", "Free"}).
+		DigestItem("Recommended", func(item *mailgen.Builder) {
+			item.Line("You might also like Mailgen.").Action("Shop now", "https://example.com/mailgen")
+		})
+
+	data, err := json.Marshal(original)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"type":"line"`)
+	assert.Contains(t, string(data), `"type":"table"`)
+	assert.Contains(t, string(data), `"type":"action"`)
+	assert.Contains(t, string(data), `"type":"digestItem"`)
+	assert.Contains(t, string(data), `"type":"title"`)
+
+	var restored mailgen.Builder
+	require.NoError(t, json.Unmarshal(data, &restored))
+
+	originalMsg, err := original.Build()
+	require.NoError(t, err)
+	restoredMsg, err := restored.Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, originalMsg.Subject(), restoredMsg.Subject())
+	assert.Equal(t, originalMsg.From(), restoredMsg.From())
+	assert.Equal(t, originalMsg.To(), restoredMsg.To())
+	assert.Equal(t, originalMsg.Cc(), restoredMsg.Cc())
+	assert.Equal(t, originalMsg.HTML(), restoredMsg.HTML())
+	assert.Equal(t, originalMsg.PlainText(), restoredMsg.PlainText())
+
+	original = mailgen.New().
+		Locale("de").
+		BrandColor("#FF0000").
+		Font("Georgia, serif").
+		Colors("#333333", "#F4F4F4").
+		DarkMode(false).
+		PlainTextHeader(false).
+		Header("X-A", "1").
+		Header("X-A", "2").
+		MessageID("order-1@example.com").
+		Priority(mailgen.PriorityHigh).
+		Unsubscribe("https://example.com/unsubscribe", mailgen.UnsubscribeOption{ShowLink: true, Mailto: "unsub@example.com"}).
+		PreferenceCenter("https://example.com/preferences", "Preferences", mailgen.PreferenceCenterOption{Header: true}).
+		ViewInBrowser("https://example.com/view").
+		CopyrightSuffix("").
+		FallbackPlacement(mailgen.FallbackAfterAction).
+		PlainTextNewlineMode(mailgen.NewlineSingle).
+		WrapText(60).
+		AllowedLinkSchemes("https", "tel").
+		LinkParams(map[string]string{"utm_source": "email"}).
+		OverrideLinkParams(true).
+		ClipThreshold(50000).
+		Action("Call us", "tel:+15555550100").
+		Action("View Order", "https://example.com/orders/1?utm_source=app")
+
+	data, err = json.Marshal(original)
+	require.NoError(t, err)
+	restored = mailgen.Builder{}
+	require.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, original.ToSpec(), restored.ToSpec())
+
+	originalMsg, err = original.Build()
+	require.NoError(t, err)
+	restoredMsg, err = restored.Build()
+	require.NoError(t, err)
+	assert.Equal(t, originalMsg.Headers(), restoredMsg.Headers())
+	assert.Equal(t, []string{"1", "2"}, restoredMsg.Headers()["X-A"])
+	assert.Equal(t, originalMsg.HTML(), restoredMsg.HTML())
+	assert.Equal(t, originalMsg.PlainText(), restoredMsg.PlainText())
+}
+
+func TestFromSpec(t *testing.T) {
+	data := `{
+		"subject": "Welcome",
+		"to": ["john@example.com"],
+		"name": "John",
+		"components": [
+			{"type": "line", "text": "Welcome to Go-Mailgen!"},
+			{"type": "action", "text": "Get Started", "link": "https://example.com/start"},
+			{"type": "coupon", "code": "WELCOME10"}
+		]
+	}`
+	var spec mailgen.BuilderSpec
+	require.NoError(t, json.Unmarshal([]byte(data), &spec))
+
+	msg, err := mailgen.FromSpec(spec).Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, "Welcome", msg.Subject())
+	assert.Equal(t, []string{"john@example.com"}, msg.To())
+	assert.Contains(t, msg.PlainText(), "Hi John,", "Greeting should keep the default")
+	assert.Contains(t, msg.PlainText(), "Welcome to Go-Mailgen!")
+	assert.Contains(t, msg.PlainText(), "Get Started (https://example.com/start)")
+	assert.Contains(t, msg.PlainText(), "[ WELCOME10 ]")
+	assert.Contains(
+		t,
+		msg.HTML(),
+		"If you&#39;re having trouble clicking the &#34;Get Started&#34; button",
+		"Actions from a spec should have fallbacks",
+	)
+}
+
+func TestComponentSpec_UnknownType(t *testing.T) {
+	var spec mailgen.BuilderSpec
+	err := json.Unmarshal([]byte(`{"components": [{"type": "video"}]}`), &spec)
+	require.ErrorIs(t, err, mailgen.ErrUnknownComponentType)
+	assert.Contains(t, err.Error(), `"video"`)
+
+	_, err = json.Marshal(mailgen.ComponentSpec{Component: customComponent{}})
+	require.ErrorIs(t, err, mailgen.ErrUnknownComponentType)
+}
+
+type customComponent struct{}
+
+func (customComponent) HTML(*htmltemplate.Template) (string, error) { return "", nil }
+
+func (customComponent) PlainText() (string, error) { return "", nil }
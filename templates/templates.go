@@ -3,6 +3,7 @@ package templates
 import (
 	"embed"
 	htmltemplate "html/template"
+	"maps"
 	"strings"
 	texttemplate "text/template"
 	"unicode"
@@ -29,15 +30,39 @@ var PlainHTMLTmpl = htmltemplate.Must(htmltemplate.New("index.html").
 	ParseFS(plainFS, "plain/*.html"),
 )
 
+//go:embed amp/*
+var ampFS embed.FS
+
+// AMPHTMLTmpl renders the AMP for Email variant of the default theme.
+var AMPHTMLTmpl = htmltemplate.Must(htmltemplate.New("index.html").
+	Funcs(htmlTemplateFuncs).
+	ParseFS(ampFS, "amp/*.html"),
+)
+
 var htmlTemplateFuncs = htmltemplate.FuncMap{
 	"buttonVariantClass": buttonVariantClass,
 	"capitalize":         capitalize,
+	"isURL":              IsURL,
+	"odd":                odd,
 }
 var textTemplateFuncs = texttemplate.FuncMap{
-	"boxString": boxString,
+	"boxString": BoxString,
 	"concat":    concat,
 }
 
+// HTMLFuncs returns the functions available to the built-in HTML templates, e.g. "capitalize" and "isURL".
+// Custom themes can add them before parsing to reuse the built-in templates, together with their own functions.
+// Functions added later with Funcs replace built-in functions of the same name.
+func HTMLFuncs() htmltemplate.FuncMap {
+	return maps.Clone(htmlTemplateFuncs)
+}
+
+// TextFuncs returns the functions available to the built-in plain text templates, e.g. "boxString" and "concat".
+// Functions added later with Funcs replace built-in functions of the same name.
+func TextFuncs() texttemplate.FuncMap {
+	return maps.Clone(textTemplateFuncs)
+}
+
 func capitalize(s string) string {
 	if s == "" {
 		return ""
@@ -47,6 +72,15 @@ func capitalize(s string) string {
 	return string(runes)
 }
 
+// IsURL reports whether s is a http or https URL.
+func IsURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+func odd(i int) bool {
+	return i%2 == 1
+}
+
 func concat(a, b string) string {
 	return a + b
 }
@@ -62,7 +96,8 @@ func buttonVariantClass(color string) string {
 	}
 }
 
-func boxString(s string) string {
+// BoxString surrounds s with a border of asterisks matching its longest line.
+func BoxString(s string) string {
 	// Find the max line length (in case of multi-line input)
 	lines := strings.Split(s, "\n")
 	maxLen := 0
@@ -1,6 +1,7 @@
 package mailgen
 
 import (
+	"fmt"
 	htmltemplate "html/template"
 	"strings"
 	"sync"
@@ -11,10 +12,18 @@ import (
 
 // Theme defines HTML and plain text templates for a named mail theme.
 //
-// HTML must include all component templates (for example: index.html, line, button, table)
+// HTML must include all component templates (index.html, line, button, table)
 // expected by the builder.
 //
 // PlainText is optional. If omitted, the default plain-text template will be used.
+//
+// Template functions, e.g. for date or currency formatting, must be added before parsing with Funcs.
+// templates.HTMLFuncs and templates.TextFuncs return the functions of the built-in templates, so that custom
+// functions can be used alongside them; a custom function with the same name as a built-in one replaces it.
+//
+// Content added via the Builder is passed to the templates as data and never parsed,
+// so it may contain literal "{{" and "}}". If the templates themselves need to contain
+// literal "{{" and "}}", parse them with custom delimiters, e.g. htmltemplate.New("index.html").Delims("[[", "]]").
 type Theme struct {
 	HTML      *htmltemplate.Template
 	PlainText *texttemplate.Template
@@ -34,8 +43,13 @@ var (
 	}
 )
 
+// requiredHTMLTemplates lists the templates a theme HTML template must define.
+var requiredHTMLTemplates = []string{"index.html", "button", "line", "table"}
+
 // RegisterTheme registers a custom theme that can be selected via Builder.Theme(name).
 // Name is case-insensitive and stored in lowercase.
+//
+// Returns ErrMissingThemeTemplate if the HTML template does not define all required templates.
 func RegisterTheme(name string, theme Theme) error {
 	name = normalizeThemeName(name)
 	if name == "" {
@@ -44,6 +58,11 @@ func RegisterTheme(name string, theme Theme) error {
 	if theme.HTML == nil {
 		return ErrNilHTMLTemplate
 	}
+	for _, tmplName := range requiredHTMLTemplates {
+		if theme.HTML.Lookup(tmplName) == nil {
+			return fmt.Errorf("%w: %q", ErrMissingThemeTemplate, tmplName)
+		}
+	}
 	if theme.PlainText == nil {
 		theme.PlainText = templates.DefaultPlainTextTmpl
 	}
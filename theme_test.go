@@ -6,6 +6,7 @@ import (
 	texttemplate "text/template"
 
 	"github.com/akfaiz/go-mailgen"
+	"github.com/akfaiz/go-mailgen/templates"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -14,6 +15,8 @@ func TestRegisterTheme_CustomTheme(t *testing.T) {
 	htmlTmpl := htmltemplate.Must(htmltemplate.New("index.html").Parse(`
 		{{define "index.html"}}CUSTOM_HTML|{{.Greeting}}|{{range .ComponentsHTML}}{{.}}{{end}}{{end}}
 		{{define "line"}}<span>CUSTOM_LINE:{{.Text}}</span>{{end}}
+		{{define "button"}}<a href="{{.Link}}">{{.Text}}</a>{{end}}
+		{{define "table"}}{{end}}
 	`))
 	textTmpl := texttemplate.Must(texttemplate.New("index.txt").Parse(`
 		{{define "index.txt"}}CUSTOM_TEXT|{{.Greeting}}|{{range .ComponentsText}}{{.}}{{end}}{{end}}
@@ -42,6 +45,8 @@ func TestRegisterTheme_DefaultPlainTextFallback(t *testing.T) {
 	htmlTmpl := htmltemplate.Must(htmltemplate.New("index.html").Parse(`
 		{{define "index.html"}}CUSTOM_HTML_ONLY|{{range .ComponentsHTML}}{{.}}{{end}}{{end}}
 		{{define "line"}}<p>{{.Text}}</p>{{end}}
+		{{define "button"}}<a href="{{.Link}}">{{.Text}}</a>{{end}}
+		{{define "table"}}{{end}}
 	`))
 
 	err := mailgen.RegisterTheme("html-only-theme", mailgen.Theme{HTML: htmlTmpl})
@@ -63,4 +68,82 @@ func TestRegisterTheme_Validation(t *testing.T) {
 
 	err = mailgen.RegisterTheme("nil-html", mailgen.Theme{})
 	require.ErrorIs(t, err, mailgen.ErrNilHTMLTemplate)
+
+	missingTable := htmltemplate.Must(htmltemplate.New("index.html").Parse(`
+		{{define "index.html"}}{{range .ComponentsHTML}}{{.}}{{end}}{{end}}
+		{{define "line"}}<p>{{.Text}}</p>{{end}}
+		{{define "button"}}<a href="{{.Link}}">{{.Text}}</a>{{end}}
+	`))
+	err = mailgen.RegisterTheme("missing-table", mailgen.Theme{HTML: missingTable})
+	require.ErrorIs(t, err, mailgen.ErrMissingThemeTemplate)
+	assert.Contains(t, err.Error(), `"table"`)
+
+	msg, err := mailgen.New().Theme("missing-table").Line("Hello").Build()
+	require.NoError(t, err)
+	assert.Contains(t, msg.HTML(), "email-wrapper", "Unregistered theme should fall back to the default theme")
+}
+
+func TestRegisterTheme_CustomDelimiters(t *testing.T) {
+	htmlTmpl := htmltemplate.Must(htmltemplate.New("index.html").Delims("[[", "]]").Parse(`
+		[[define "index.html"]]<p>Syntax: {{ .Secret }}</p>[[range .ComponentsHTML]][[.]][[end]][[end]]
+		[[define "line"]]<p>[[.Text]]</p>[[end]]
+		[[define "button"]]<a href="[[.Link]]">[[.Text]]</a>[[end]]
+		[[define "table"]][[end]]
+	`))
+	err := mailgen.RegisterTheme("custom-delims", mailgen.Theme{HTML: htmlTmpl})
+	require.NoError(t, err)
+
+	msg, err := mailgen.New().
+		Theme("custom-delims").
+		UsePremailer(false).
+		Line("Reference secrets with {{ secrets.TOKEN }} in your workflow.").
+		Build()
+	require.NoError(t, err)
+
+	assert.Contains(t, msg.HTML(), "<p>Syntax: {{ .Secret }}</p>", "Template should keep literal delimiters")
+	assert.Contains(t, msg.HTML(), "<p>Reference secrets with {{ secrets.TOKEN }} in your workflow.</p>")
+	assert.Contains(t, msg.PlainText(), "Reference secrets with {{ secrets.TOKEN }} in your workflow.")
+}
+
+func TestRegisterTheme_CustomFuncs(t *testing.T) {
+	htmlTmpl := htmltemplate.Must(htmltemplate.New("index.html").
+		Funcs(templates.HTMLFuncs()).
+		Funcs(htmltemplate.FuncMap{
+			"shout":      func(s string) string { return s + "!" },
+			"capitalize": func(s string) string { return "CUSTOM " + s },
+		}).
+		Parse(`
+		{{define "index.html"}}{{shout .Product.Name}}|{{capitalize "hello"}}|{{range .ComponentsHTML}}{{.}}{{end}}{{end}}
+		{{define "line"}}<p>{{if isURL .Text}}URL{{else}}{{.Text}}{{end}}</p>{{end}}
+		{{define "button"}}<a href="{{.Link}}">{{.Text}}</a>{{end}}
+		{{define "table"}}{{end}}
+	`))
+	textTmpl := texttemplate.Must(texttemplate.New("index.txt").
+		Funcs(templates.TextFuncs()).
+		Parse(`{{define "index.txt"}}{{boxString .Product.Name}}{{end}}`))
+	err := mailgen.RegisterTheme("custom-funcs", mailgen.Theme{HTML: htmlTmpl, PlainText: textTmpl})
+	require.NoError(t, err)
+
+	msg, err := mailgen.New().
+		Theme("custom-funcs").
+		UsePremailer(false).
+		Product(mailgen.Product{Name: "Acme"}).
+		Line("https://example.com").
+		Build()
+	require.NoError(t, err)
+
+	assert.Contains(t, msg.HTML(), "Acme!|CUSTOM hello|<p>URL</p>", "Custom functions should replace built-in ones")
+	assert.Equal(t, "****\nAcme\n****", msg.PlainText())
+}
+
+func TestBuilder_LiteralDelimitersInContent(t *testing.T) {
+	msg, err := mailgen.New().
+		Line("Build failed: {{ .Steps.test.outcome }}").
+		Action("View {{ run }}", "https://example.com/runs/1").
+		Build()
+	require.NoError(t, err)
+
+	assert.Contains(t, msg.HTML(), "Build failed: {{ .Steps.test.outcome }}")
+	assert.Contains(t, msg.HTML(), "View {{ run }}")
+	assert.Contains(t, msg.PlainText(), "Build failed: {{ .Steps.test.outcome }}")
 }